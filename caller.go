@@ -0,0 +1,64 @@
+package kemba
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Caller controls the annotation of log events with the location of the call that produced them.
+type Caller int
+
+const (
+	// CallerNone disables the caller annotation.
+	CallerNone Caller = iota
+	// CallerFile annotates log events with the file name and line of the call, ex. main.go:42
+	CallerFile
+	// CallerFunc annotates log events with the file name, line and function of the call, ex. main.go:42 main.run
+	CallerFunc
+)
+
+// callerDepth is the number of frames to skip to reach the caller of the public logging
+// methods: callerPC, log and the public logging method itself.
+const callerDepth = 3
+
+// callerPC returns the program counter of the function skip frames above callerPC.
+// Every public logging method must call log directly for callerDepth to be correct.
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// formatCaller will render the location of the program counter according to the caller mode.
+func formatCaller(pc uintptr, mode Caller) string {
+	if pc == 0 || mode == CallerNone {
+		return ""
+	}
+
+	frames := runtime.CallersFrames([]uintptr{pc})
+	f, _ := frames.Next()
+
+	loc := fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+	if mode == CallerFunc && f.Function != "" {
+		loc = fmt.Sprintf("%s %s", loc, f.Function[strings.LastIndex(f.Function, "/")+1:])
+	}
+
+	return loc
+}
+
+// getCallerFromEnv will read the caller mode from the KEMBA_CALLER env value, or the configured Env.Caller.
+// A value of "func" includes the function name, any other value enables the file and line.
+func getCallerFromEnv() Caller {
+	switch strings.ToLower(getenv(currentEnv().Caller)) {
+	case "", "0", "false":
+		return CallerNone
+	case "func":
+		return CallerFunc
+	default:
+		return CallerFile
+	}
+}
@@ -0,0 +1,98 @@
+package kemba
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// regexpDelta matches the time delta of text output.
+var regexpDelta = regexp.MustCompile(`\+\d+\S+`)
+
+// line returns the line of the call to line.
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+func Test_Caller(t *testing.T) {
+	is := assert.New(t)
+
+	newLogger := func(buf *bytes.Buffer, mode Caller) *Kemba {
+		return NewWithOptions("test:kemba", WithWriter(buf), WithAllowed("test:*"), WithColor(false), WithCaller(mode))
+	}
+
+	t.Run("should annotate Printf, Println, Log and Logw with the calling line", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := newLogger(&buf, CallerFile)
+
+		k.Printf("test")
+		l := line() - 1
+		k.Println("test")
+		k.Log("test")
+		k.Logw("test")
+
+		is.Equal(fmt.Sprintf(
+			"test:kemba test +0s caller_test.go:%d\n"+
+				"test:kemba test +0s caller_test.go:%d\n"+
+				"test:kemba test +0s caller_test.go:%d\n"+
+				"test:kemba test +0s caller_test.go:%d\n",
+			l, l+2, l+3, l+4,
+		), regexpDelta.ReplaceAllString(buf.String(), "+0s"))
+	})
+
+	t.Run("should annotate with the function name", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := newLogger(&buf, CallerFunc)
+
+		k.Printf("test")
+		l := line() - 1
+
+		is.Regexp(fmt.Sprintf(`^test:kemba test \+\S+ caller_test.go:%d kemba.Test_Caller.func\d+\n$`, l), buf.String())
+	})
+
+	t.Run("should only annotate the first line", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := newLogger(&buf, CallerFile)
+
+		k.Printf("first\nsecond")
+
+		is.Regexp(`^test:kemba first \+\S+ caller_test.go:\d+\ntest:kemba second\n$`, buf.String())
+	})
+
+	t.Run("should annotate slog records", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(newLogger(&buf, CallerFile).Handler())
+
+		logger.Info("test")
+		l := line() - 1
+
+		is.Regexp(fmt.Sprintf(`^test:kemba test level=INFO \+\S+ caller_test.go:%d\n$`, l), buf.String())
+	})
+
+	t.Run("should not annotate by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+
+		k.Printf("test")
+
+		is.Regexp(`^test:kemba test \+\S+\n$`, buf.String())
+	})
+
+	t.Run("should read the mode from KEMBA_CALLER", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_CALLER", "1")
+		is.Equal(CallerFile, getCallerFromEnv())
+
+		_ = os.Setenv("KEMBA_CALLER", "func")
+		is.Equal(CallerFunc, getCallerFromEnv())
+
+		_ = os.Setenv("KEMBA_CALLER", "")
+		is.Equal(CallerNone, getCallerFromEnv())
+	})
+}
@@ -0,0 +1,149 @@
+package kemba
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// ColorLevel is the color capability of the output.
+type ColorLevel int
+
+const (
+	// ColorNone disables colors.
+	ColorNone ColorLevel = iota
+	// Color16 renders the palette with the 16 basic ANSI colors.
+	Color16
+	// Color256 renders the palette with the 256 ANSI colors.
+	Color256
+	// ColorTrue renders the palette with 24 bit colors.
+	ColorTrue
+)
+
+// basicTerms are the TERM values of terminals that only support the 16 basic colors.
+var basicTerms = map[string]bool{
+	"ansi":        true,
+	"cygwin":      true,
+	"linux":       true,
+	"screen":      true,
+	"vt100":       true,
+	"xterm":       true,
+	"xterm-color": true,
+}
+
+// detectColorLevel will determine the color capability of the writer from the environment.
+//
+// NO_COLOR and NOCOLOR disable colors. FORCE_COLOR enables colors regardless of the writer, with
+// 1, 2 and 3 requesting at least 16, 256 and 24 bit colors, and 0 or false disabling them.
+// Otherwise colors are only enabled when the writer is a terminal, and TERM is not dumb.
+func detectColorLevel(w io.Writer) ColorLevel {
+	if os.Getenv("NO_COLOR") != "" || getenv(currentEnv().NoColor) != "" {
+		return ColorNone
+	}
+
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		var min ColorLevel
+		switch strings.ToLower(force) {
+		case "0", "false":
+			return ColorNone
+		case "", "1", "true":
+			min = Color16
+		default:
+			if n, err := strconv.Atoi(force); err == nil && n >= int(ColorTrue) {
+				min = ColorTrue
+			} else {
+				min = Color256
+			}
+		}
+
+		if l := termColorLevel(); l > min {
+			return l
+		}
+		return min
+	}
+
+	if os.Getenv("TERM") == "dumb" {
+		return ColorNone
+	}
+
+	if f, ok := w.(*os.File); !ok || !isTerminal(f) {
+		return ColorNone
+	}
+
+	return termColorLevel()
+}
+
+// termColorLevel will determine the color capability of the terminal from COLORTERM and TERM.
+// Unknown terminals are assumed to support 256 colors.
+func termColorLevel() ColorLevel {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+
+	if basicTerms[os.Getenv("TERM")] {
+		return Color16
+	}
+
+	return Color256
+}
+
+// paint will wrap the string with the escape codes of the color, converted to the color level.
+func paint(level ColorLevel, c color.Color256, s string) string {
+	if level == ColorNone || s == "" {
+		return s
+	}
+
+	var code string
+	switch level {
+	case Color16:
+		code = strconv.Itoa(c256To16(c.Value()))
+	case ColorTrue:
+		code = c.RGB().String()
+	default:
+		code = c.String()
+	}
+
+	return color.StartSet + code + "m" + s + color.ResetSet
+}
+
+// c256To16 will convert a 256 color value to the foreground code of the closest basic ANSI color.
+//
+// Values of the 6x6x6 color cube keep the channels within one step of the brightest channel,
+// and are bright when that channel is in the upper half of the cube.
+func c256To16(v uint8) int {
+	n := int(v)
+	switch {
+	case n < 8:
+		return 30 + n
+	case n < 16:
+		return 90 + n - 8
+	case n >= 232:
+		return []int{30, 90, 37, 97}[(n-232)/6]
+	}
+
+	i := n - 16
+	rgb := []int{i / 36, i / 6 % 6, i % 6}
+
+	m := 0
+	for _, ch := range rgb {
+		if ch > m {
+			m = ch
+		}
+	}
+
+	code := 30
+	for bit, ch := range rgb {
+		if ch > 0 && ch >= m-1 {
+			code += 1 << bit
+		}
+	}
+	if m >= 4 {
+		code += 60
+	}
+
+	return code
+}
@@ -0,0 +1,147 @@
+package kemba
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Private_detectColorLevel(t *testing.T) {
+	is := assert.New(t)
+
+	// clearColorEnv will unset every variable considered by detectColorLevel for the test.
+	clearColorEnv := func(t *testing.T) {
+		for _, name := range []string{"NO_COLOR", "NOCOLOR", "FORCE_COLOR", "TERM", "COLORTERM"} {
+			t.Setenv(name, "")
+			_ = os.Unsetenv(name)
+		}
+	}
+
+	t.Run("should disable colors for writers that are not terminals", func(t *testing.T) {
+		clearColorEnv(t)
+
+		r, w, _ := os.Pipe()
+		defer func() {
+			_ = r.Close()
+			_ = w.Close()
+		}()
+
+		is.Equal(ColorNone, detectColorLevel(w))
+		is.Equal(ColorNone, detectColorLevel(&bytes.Buffer{}))
+	})
+
+	t.Run("should honor NO_COLOR and NOCOLOR over FORCE_COLOR", func(t *testing.T) {
+		clearColorEnv(t)
+		t.Setenv("FORCE_COLOR", "3")
+
+		t.Setenv("NO_COLOR", "1")
+		is.Equal(ColorNone, detectColorLevel(&bytes.Buffer{}))
+
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("NOCOLOR", "1")
+		is.Equal(ColorNone, detectColorLevel(&bytes.Buffer{}))
+	})
+
+	t.Run("should force colors with FORCE_COLOR", func(t *testing.T) {
+		clearColorEnv(t)
+		t.Setenv("TERM", "xterm")
+
+		tests := map[string]ColorLevel{
+			"":      Color16,
+			"1":     Color16,
+			"true":  Color16,
+			"2":     Color256,
+			"3":     ColorTrue,
+			"0":     ColorNone,
+			"false": ColorNone,
+		}
+		for value, level := range tests {
+			t.Setenv("FORCE_COLOR", value)
+			is.Equal(level, detectColorLevel(&bytes.Buffer{}), value)
+		}
+	})
+
+	t.Run("should raise forced colors to the terminal capability", func(t *testing.T) {
+		clearColorEnv(t)
+		t.Setenv("FORCE_COLOR", "1")
+		t.Setenv("COLORTERM", "truecolor")
+
+		is.Equal(ColorTrue, detectColorLevel(&bytes.Buffer{}))
+	})
+
+	t.Run("should detect the terminal capability", func(t *testing.T) {
+		clearColorEnv(t)
+		is.Equal(Color256, termColorLevel())
+
+		t.Setenv("TERM", "xterm-256color")
+		is.Equal(Color256, termColorLevel())
+
+		t.Setenv("TERM", "linux")
+		is.Equal(Color16, termColorLevel())
+
+		t.Setenv("COLORTERM", "24bit")
+		is.Equal(ColorTrue, termColorLevel())
+	})
+}
+
+func Test_Private_paint(t *testing.T) {
+	is := assert.New(t)
+
+	c := color.C256(81)
+
+	t.Run("should render each color level", func(t *testing.T) {
+		is.Equal("test", paint(ColorNone, c, "test"))
+		is.Equal("\x1b[96mtest\x1b[0m", paint(Color16, c, "test"))
+		is.Equal("\x1b[38;5;81mtest\x1b[0m", paint(Color256, c, "test"))
+		is.Equal("\x1b[38;2;95;215;255mtest\x1b[0m", paint(ColorTrue, c, "test"))
+	})
+
+	t.Run("should not paint empty strings", func(t *testing.T) {
+		is.Equal("", paint(Color256, c, ""))
+	})
+}
+
+func Test_WithColorLevel(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should render the prefix with the color level", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColorLevel(Color16), WithTime(TimeNone))
+		k.Printf("test")
+
+		is.True(k.color)
+		is.Equal("\x1b[96mtest:kemba \x1b[0mtest\n", buf.String())
+	})
+
+	t.Run("should disable colors for buffers by default", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "")
+		_ = os.Unsetenv("FORCE_COLOR")
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"))
+		k.Printf("test")
+
+		is.False(k.color)
+		is.NotContains(buf.String(), "\x1b[")
+	})
+}
+
+func Test_Private_c256To16(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should convert to the closest basic color", func(t *testing.T) {
+		is.Equal(31, c256To16(1))
+		is.Equal(97, c256To16(15))
+		is.Equal(94, c256To16(21))
+		is.Equal(96, c256To16(81))
+		is.Equal(91, c256To16(196))
+		is.Equal(92, c256To16(46))
+		is.Equal(95, c256To16(201))
+		is.Equal(30, c256To16(16))
+		is.Equal(90, c256To16(240))
+		is.Equal(97, c256To16(255))
+	})
+}
@@ -0,0 +1,203 @@
+package kemba
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer that records every write as a separate chunk.
+type syncBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes []string
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.writes = append(b.writes, string(p))
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func Test_Concurrency(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should emit each multi-line event as a single write", func(t *testing.T) {
+		var out syncBuffer
+		k := NewWithOptions("test:kemba", WithWriter(&out), WithAllowed("test:*"), WithColor(false))
+
+		type myType struct {
+			a, b int
+		}
+		x := []myType{{1, 2}, {3, 4}, {5, 6}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					k.Printf("%# v", x)
+					k.Println("a string", 12, true)
+				}
+			}()
+		}
+		wg.Wait()
+
+		is.Len(out.writes, 20*50*2)
+		for _, w := range out.writes {
+			lines := strings.Split(strings.TrimSuffix(w, "\n"), "\n")
+			if strings.Contains(lines[0], "myType") {
+				is.Len(lines, 5)
+				is.Equal("test:kemba }", lines[4])
+			} else {
+				is.Len(lines, 3)
+				is.Equal("test:kemba bool(true)", lines[2])
+			}
+		}
+	})
+
+	t.Run("should not interleave lines across loggers sharing a writer", func(t *testing.T) {
+		var out syncBuffer
+		k := NewWithOptions("test:kemba", WithWriter(&out), WithAllowed("test:*"), WithColor(false))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(ke *Kemba) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					ke.With("j", j).Printf("first\nsecond")
+				}
+			}(k.Extend("child"))
+		}
+		wg.Wait()
+
+		re := regexp.MustCompile(`^(test:kemba:child first j=\d+ \+\S+\ntest:kemba:child second\n)+$`)
+		is.Regexp(re, out.String())
+	})
+
+	t.Run("should allow toggling while logging", func(t *testing.T) {
+		defer registry.set(nil)
+
+		var out syncBuffer
+		k := NewWithOptions("test:kemba", WithWriter(&out), WithColor(true))
+		h := k.Extend("slog").Handler()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					k.Log("test")
+					k.Logw("test", "j", j)
+					_ = h.Enabled(context.Background(), slog.LevelDebug)
+				}
+			}()
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					if (i+j)%2 == 0 {
+						Enable("test:*")
+					} else {
+						_ = Disable()
+					}
+					_ = Enabled("test:kemba")
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	t.Run("should allow chaining loggers through their writers", func(t *testing.T) {
+		var out syncBuffer
+		outer := NewWithOptions("test:outer", WithWriter(&out), WithAllowed("test:*"), WithColor(false), WithTime(TimeNone))
+
+		writers := map[string]io.Writer{
+			"Writer":    outer.Writer(),
+			"StdLogger": outer.StdLogger().Writer(),
+		}
+		for name, w := range writers {
+			inner := NewWithOptions("test:inner", WithWriter(w), WithAllowed("test:*"), WithColor(false), WithTime(TimeNone))
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				inner.Printf("through %s", name)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("chaining through %s deadlocked", name)
+			}
+			is.Contains(out.String(), "test:outer test:inner through "+name+"\n")
+		}
+	})
+
+	t.Run("should not block loggers on the writer of another logger", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		slow := NewWithOptions("test:slow", WithWriter(blockingWriter(block)), WithAllowed("test:*"))
+		go slow.Printf("blocked")
+
+		var out syncBuffer
+		k := NewWithOptions("test:fast", WithWriter(&out), WithAllowed("test:*"), WithColor(false))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			k.Printf("not blocked")
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("a slow writer blocked another logger")
+		}
+	})
+
+	t.Run("should not retain the writers of discarded loggers", func(t *testing.T) {
+		var collected int32
+		for i := 0; i < 10; i++ {
+			buf := &bytes.Buffer{}
+			runtime.SetFinalizer(buf, func(*bytes.Buffer) { atomic.AddInt32(&collected, 1) })
+			NewWithOptions("test:kemba", WithWriter(buf), WithAllowed("test:*")).Printf("test")
+		}
+
+		for i := 0; i < 20 && atomic.LoadInt32(&collected) < 10; i++ {
+			runtime.GC()
+			time.Sleep(time.Millisecond)
+		}
+		is.Equal(int32(10), atomic.LoadInt32(&collected))
+	})
+}
+
+// blockingWriter is an io.Writer blocking until the channel is closed.
+type blockingWriter chan struct{}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w
+	return len(p), nil
+}
@@ -0,0 +1,142 @@
+package kemba
+
+import (
+	"os"
+	"sync"
+)
+
+// EnvPrecedence controls how the values of the pattern environment variables are combined.
+type EnvPrecedence int
+
+const (
+	// EnvMerge joins the values of every pattern environment variable that is set, in order.
+	EnvMerge EnvPrecedence = iota
+	// EnvFirst uses the value of the first pattern environment variable that is set.
+	EnvFirst
+)
+
+// Env holds the names of the environment variables read by kemba.
+type Env struct {
+	// Patterns are the variables holding the namespace patterns. Defaults to DEBUG and KEMBA.
+	Patterns []string
+	// Precedence controls how the values of the Patterns variables are combined. Defaults to EnvMerge.
+	Precedence EnvPrecedence
+	// NoColor is the variable that disables colors when set. Defaults to NOCOLOR.
+	NoColor string
+	// Format is the variable holding the output format. Defaults to KEMBA_FORMAT.
+	Format string
+	// Caller is the variable holding the caller annotation mode. Defaults to KEMBA_CALLER.
+	Caller string
+	// Time is the variable holding the time mode. Defaults to KEMBA_TIME.
+	Time string
+	// Match is the variable holding the wildcard semantics. Defaults to KEMBA_MATCH.
+	Match string
+	// Explain is the variable that prints the decision of every logger at creation. Defaults to KEMBA_EXPLAIN.
+	Explain string
+	// Colors is the variable holding the colors assigned to namespaces. Defaults to KEMBA_COLORS.
+	Colors string
+	// Palette is the variable holding the name of the palette. Defaults to KEMBA_PALETTE.
+	Palette string
+}
+
+var (
+	envMu sync.RWMutex
+	env   = DefaultEnv()
+)
+
+// DefaultEnv returns the default names of the environment variables read by kemba.
+func DefaultEnv() Env {
+	return Env{
+		Patterns:   []string{"DEBUG", "KEMBA"},
+		Precedence: EnvMerge,
+		NoColor:    "NOCOLOR",
+		Format:     "KEMBA_FORMAT",
+		Caller:     "KEMBA_CALLER",
+		Time:       "KEMBA_TIME",
+		Match:      "KEMBA_MATCH",
+		Explain:    "KEMBA_EXPLAIN",
+		Colors:     "KEMBA_COLORS",
+		Palette:    "KEMBA_PALETTE",
+	}
+}
+
+// SetEnv will replace the names of the environment variables read by kemba and re-evaluate
+// every logger that reads its patterns from the environment.
+//
+// Example:
+//
+//	env := kemba.DefaultEnv()
+//	env.Patterns = []string{"MYAPP_DEBUG", "DEBUG"}
+//	env.Precedence = kemba.EnvFirst
+//	kemba.SetEnv(env)
+func SetEnv(e Env) {
+	envMu.Lock()
+	env = e
+	env.Patterns = append([]string(nil), e.Patterns...)
+	envMu.Unlock()
+
+	resetPickedColors()
+	registry.refresh()
+}
+
+// SetEnvPrefix will read every environment variable with the provided prefix, so that binaries
+// do not collide with other tools reading DEBUG. The namespace patterns are read from PREFIX_DEBUG,
+// and the settings from PREFIX_NOCOLOR, PREFIX_FORMAT, PREFIX_CALLER, PREFIX_TIME, PREFIX_MATCH,
+// PREFIX_EXPLAIN, PREFIX_COLORS and PREFIX_PALETTE.
+//
+// An empty prefix restores the default names.
+func SetEnvPrefix(prefix string) {
+	if prefix == "" {
+		SetEnv(DefaultEnv())
+		return
+	}
+
+	SetEnv(Env{
+		Patterns:   []string{prefix + "_DEBUG"},
+		Precedence: EnvMerge,
+		NoColor:    prefix + "_NOCOLOR",
+		Format:     prefix + "_FORMAT",
+		Caller:     prefix + "_CALLER",
+		Time:       prefix + "_TIME",
+		Match:      prefix + "_MATCH",
+		Explain:    prefix + "_EXPLAIN",
+		Colors:     prefix + "_COLORS",
+		Palette:    prefix + "_PALETTE",
+	})
+}
+
+// currentEnv returns the names of the environment variables read by kemba.
+func currentEnv() Env {
+	envMu.RLock()
+	defer envMu.RUnlock()
+
+	return env
+}
+
+// getenv will read the environment variable, treating an empty name as unset.
+func getenv(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// envSources returns the values of the pattern environment variables that apply,
+// according to the precedence rules.
+func envSources() []patternSource {
+	e := currentEnv()
+
+	var sources []patternSource
+	for _, name := range e.Patterns {
+		v := getenv(name)
+		if v == "" {
+			continue
+		}
+
+		sources = append(sources, patternSource{name: name, patterns: v})
+		if e.Precedence == EnvFirst {
+			break
+		}
+	}
+	return sources
+}
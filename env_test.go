@@ -0,0 +1,140 @@
+package kemba
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pkgLogger is created before the environment names are set, as package level loggers are.
+var pkgLogger = New("test:pkg")
+
+func Test_SetEnvPrefix(t *testing.T) {
+	is := assert.New(t)
+	defer SetEnv(DefaultEnv())
+
+	t.Run("should read the prefixed variables", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "other:*")
+		_ = os.Setenv("MYAPP_DEBUG", "test:*")
+		_ = os.Setenv("MYAPP_NOCOLOR", "1")
+		_ = os.Setenv("MYAPP_FORMAT", "json")
+		SetEnvPrefix("MYAPP")
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf))
+		k.Printf("test")
+
+		is.True(k.enabled, "Logger should be enabled")
+		is.False(k.color)
+		is.Equal("test:*", getDebugFlagFromEnv())
+		is.Regexp(`^\{"time":"[^"]+","ns":"test:kemba","msg":"test","delta_ms":\d+\}\n$`, buf.String())
+		is.False(New("other:kemba").enabled, "Logger should NOT be enabled")
+
+		_ = os.Setenv("DEBUG", "")
+		_ = os.Setenv("MYAPP_DEBUG", "")
+		_ = os.Setenv("MYAPP_NOCOLOR", "")
+		_ = os.Setenv("MYAPP_FORMAT", "")
+	})
+
+	t.Run("should restore the default names", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*")
+		SetEnvPrefix("MYAPP")
+		SetEnvPrefix("")
+
+		is.Equal(DefaultEnv(), currentEnv())
+		is.Equal("test:*", getDebugFlagFromEnv())
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should re-evaluate existing loggers", func(t *testing.T) {
+		_ = os.Setenv("MYAPP_DEBUG", "test:*")
+
+		k := New("test:kemba")
+		is.False(k.enabled, "Logger should NOT be enabled")
+
+		SetEnvPrefix("MYAPP")
+		is.True(k.isEnabled(), "Logger should be enabled")
+
+		SetEnvPrefix("")
+		is.False(k.isEnabled(), "Logger should NOT be enabled")
+
+		_ = os.Setenv("MYAPP_DEBUG", "")
+	})
+
+	t.Run("should re-resolve the settings of package level loggers", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "stderr")
+		is.NoError(err)
+		defer func() { _ = f.Close() }()
+
+		stderr := os.Stderr
+		os.Stderr = f
+		defer func() {
+			os.Stderr = stderr
+			registry.refresh()
+		}()
+
+		_ = os.Setenv("MYAPP_DEBUG", "test:*")
+		_ = os.Setenv("MYAPP_FORMAT", "json")
+		_ = os.Setenv("MYAPP_CALLER", "1")
+		SetEnvPrefix("MYAPP")
+
+		pkgLogger.Printf("test")
+		SetEnvPrefix("")
+
+		b, err := os.ReadFile(f.Name())
+		is.NoError(err)
+		is.Regexp(`^\{"time":"[^"]+","ns":"test:pkg","msg":"test","delta_ms":\d+,"caller":"env_test\.go:\d+"\}\n$`, string(b))
+
+		_ = os.Setenv("MYAPP_DEBUG", "")
+		_ = os.Setenv("MYAPP_FORMAT", "")
+		_ = os.Setenv("MYAPP_CALLER", "")
+	})
+}
+
+func Test_SetEnv(t *testing.T) {
+	is := assert.New(t)
+	defer SetEnv(DefaultEnv())
+
+	t.Run("should merge the pattern variables in order", func(t *testing.T) {
+		_ = os.Setenv("MYAPP_DEBUG", "myapp:*")
+		_ = os.Setenv("DEBUG", "debug:*")
+
+		env := DefaultEnv()
+		env.Patterns = []string{"MYAPP_DEBUG", "DEBUG"}
+		SetEnv(env)
+
+		is.Equal("myapp:*,debug:*", getDebugFlagFromEnv())
+
+		_ = os.Setenv("MYAPP_DEBUG", "")
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should use the first pattern variable that is set", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "debug:*")
+		_ = os.Setenv("KEMBA", "kemba:*")
+
+		env := DefaultEnv()
+		env.Precedence = EnvFirst
+		SetEnv(env)
+
+		is.Equal("debug:*", getDebugFlagFromEnv())
+		is.Equal(`kemba:db disabled: no term matched`, Explain("kemba:db").String())
+
+		_ = os.Setenv("DEBUG", "")
+		is.Equal("kemba:*", getDebugFlagFromEnv())
+		is.Equal(`kemba:db enabled by "kemba:*" in KEMBA`, Explain("kemba:db").String())
+
+		_ = os.Setenv("KEMBA", "")
+	})
+
+	t.Run("should not be affected by changes to the provided slice", func(t *testing.T) {
+		env := DefaultEnv()
+		SetEnv(env)
+		env.Patterns[0] = "OTHER"
+
+		is.Equal("DEBUG", currentEnv().Patterns[0])
+	})
+}
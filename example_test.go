@@ -0,0 +1,211 @@
+package kemba_test
+
+import (
+	"os"
+	"time"
+
+	"github.com/clok/kemba"
+	"github.com/clok/kemba/kembatest"
+)
+
+// newExample returns a logger writing to os.Stdout with a fake clock, so that the output of the
+// examples is deterministic. An application would use kemba.New, which writes to os.Stderr.
+func newExample(tag string) (*kemba.Kemba, *kembatest.Clock) {
+	clock := kembatest.NewClock(time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC))
+	return kemba.NewWithOptions(tag, kemba.WithWriter(os.Stdout), kemba.WithClock(clock)), clock
+}
+
+func Example() {
+	_ = os.Setenv("DEBUG", "example:*")
+	// OR
+	// _ = os.Setenv("KEMBA", "example:*")
+	k, clock := newExample("example:tag")
+
+	type myType struct {
+		a, b int
+	}
+
+	var x = []myType{{1, 2}, {3, 4}}
+	k.Printf("%#v", x)
+	// Output to os.Stderr
+	// example:tag []main.myType{main.myType{a:1, b:2}, main.myType{a:3, b:4}} +0s
+
+	// Artificial delay to demonstrate the time tagging
+	clock.Advance(250 * time.Millisecond)
+	k.Printf("%# v", x)
+	k.Println(x)
+
+	// Artificial delay to demonstrate the time tagging
+	clock.Advance(100 * time.Millisecond)
+	k.Log(x)
+	// All result in the same output to os.Stderr
+	// example:tag []main.myType{ +XXs
+	// example:tag     {a:1, b:2},
+	// example:tag     {a:3, b:4},
+	// example:tag }
+
+	// Create a new extended logger with a new tag
+	k1 := k.Extend("1")
+	k1.Println("a string", 12, true)
+	// Output to os.Stderr
+	// example:tag:1 a string +0s
+	// example:tag:1 int(12)
+	// example:tag:1 bool(true)
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// example:tag []kemba_test.myType{kemba_test.myType{a:1, b:2}, kemba_test.myType{a:3, b:4}} +0s
+	// example:tag []kemba_test.myType{ +250ms
+	// example:tag     {a:1, b:2},
+	// example:tag     {a:3, b:4},
+	// example:tag }
+	// example:tag []kemba_test.myType{ +0s
+	// example:tag     {a:1, b:2},
+	// example:tag     {a:3, b:4},
+	// example:tag }
+	// example:tag []kemba_test.myType{ +100ms
+	// example:tag     {a:1, b:2},
+	// example:tag     {a:3, b:4},
+	// example:tag }
+	// example:tag:1 a string +0s
+	// example:tag:1 int(12)
+	// example:tag:1 bool(true)
+}
+
+func ExampleKemba_Printf() {
+	_ = os.Setenv("DEBUG", "test:*")
+	k, _ := newExample("test:kemba")
+	k.Printf("%s", "Hello")
+
+	k1 := k.Extend("1")
+	k1.Printf("%s", "Hello 1")
+
+	k2 := k.Extend("2")
+	k2.Printf("%s", "Hello 2")
+
+	k3 := k.Extend("3")
+	k3.Printf("%s", "Hello 3")
+
+	s := []string{"test", "again", "third"}
+	k2.Printf("%# v", s)
+
+	m := map[string]int{
+		"test":  1,
+		"again": 1337,
+		"third": 732,
+	}
+	k1.Printf("%# v", m)
+
+	type myType struct {
+		a int
+		b int
+	}
+	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+	k3.Printf("%# v", x)
+	k2.Printf("%#v", x)
+
+	k.Printf("%#v %#v %#v %#v %#v %#v", m, s, m, s, m, s)
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// test:kemba Hello +0s
+	// test:kemba:1 Hello 1 +0s
+	// test:kemba:2 Hello 2 +0s
+	// test:kemba:3 Hello 3 +0s
+	// test:kemba:2 []string{"test", "again", "third"} +0s
+	// test:kemba:1 map[string]int{"again":1337, "test":1, "third":732} +0s
+	// test:kemba:3 []kemba_test.myType{ +0s
+	// test:kemba:3     {a:1, b:2},
+	// test:kemba:3     {a:3, b:4},
+	// test:kemba:3     {a:5, b:6},
+	// test:kemba:3 }
+	// test:kemba:2 []kemba_test.myType{kemba_test.myType{a:1, b:2}, kemba_test.myType{a:3, b:4}, kemba_test.myType{a:5, b:6}} +0s
+	// test:kemba map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} +0s
+}
+
+func ExampleKemba_Printf_expanded() {
+	_ = os.Setenv("DEBUG", "test:*")
+	k, _ := newExample("test:kemba")
+	k.Printf("%s", "Hello")
+
+	type myType struct {
+		a int
+		b int
+	}
+	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+
+	// NOTE: The "%# v" operand for the Printf format.
+	k.Printf("%# v", x)
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// test:kemba Hello +0s
+	// test:kemba []kemba_test.myType{ +0s
+	// test:kemba     {a:1, b:2},
+	// test:kemba     {a:3, b:4},
+	// test:kemba     {a:5, b:6},
+	// test:kemba }
+}
+
+func ExampleKemba_Printf_compact() {
+	_ = os.Setenv("DEBUG", "test:*")
+	k, _ := newExample("test:kemba")
+
+	type myType struct {
+		a int
+		b int
+	}
+	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+
+	// NOTE: The "%#v" operand for the Printf format.
+	k.Printf("%#v", x)
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// test:kemba []kemba_test.myType{kemba_test.myType{a:1, b:2}, kemba_test.myType{a:3, b:4}, kemba_test.myType{a:5, b:6}} +0s
+}
+
+func ExampleKemba_Println() {
+	_ = os.Setenv("DEBUG", "test:*")
+	k, _ := newExample("test:kemba")
+	k.Printf("%s", "Hello")
+
+	type myType struct {
+		a int
+		b int
+	}
+	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+	k.Println(x)
+
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// test:kemba Hello +0s
+	// test:kemba []kemba_test.myType{ +0s
+	// test:kemba     {a:1, b:2},
+	// test:kemba     {a:3, b:4},
+	// test:kemba     {a:5, b:6},
+	// test:kemba }
+}
+
+func ExampleKemba_Log() {
+	_ = os.Setenv("DEBUG", "test:*")
+	k, _ := newExample("test:kemba")
+	k.Printf("%s", "Hello")
+
+	type myType struct {
+		a, b int
+	}
+	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+	k.Log(x)
+
+	_ = os.Setenv("DEBUG", "")
+
+	// Output:
+	// test:kemba Hello +0s
+	// test:kemba []kemba_test.myType{ +0s
+	// test:kemba     {a:1, b:2},
+	// test:kemba     {a:3, b:4},
+	// test:kemba     {a:5, b:6},
+	// test:kemba }
+}
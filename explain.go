@@ -0,0 +1,122 @@
+package kemba
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Explanation describes why a tag is enabled or disabled by the namespace patterns.
+type Explanation struct {
+	// Tag is the explained tag
+	Tag string
+	// Enabled reports if the tag is enabled
+	Enabled bool
+	// Source is where the deciding term was read from, ex. DEBUG, KEMBA, Enable or WithAllowed
+	Source string
+	// Term is the term that decided, prefixed with - when it is a skip. It is empty when no term matched.
+	Term string
+}
+
+// String returns a human readable description of the decision.
+func (e Explanation) String() string {
+	switch {
+	case e.Term == "":
+		return fmt.Sprintf("%s disabled: no term matched", e.Tag)
+	case e.Enabled:
+		return fmt.Sprintf("%s enabled by %q in %s", e.Tag, e.Term, e.Source)
+	default:
+		return fmt.Sprintf("%s disabled by %q in %s", e.Tag, e.Term, e.Source)
+	}
+}
+
+// patternSource is a patterns string and where it was read from.
+type patternSource struct {
+	name     string
+	patterns string
+}
+
+// Explain reports which term of the active namespace patterns, set with Enable or read from the
+// DEBUG and KEMBA environment variables, decides if the tag is enabled.
+//
+// Example:
+//
+//	// DEBUG=app:* KEMBA=-app:poller
+//	fmt.Println(kemba.Explain("app:poller"))
+//
+// Output:
+//
+//	app:poller disabled by "-app:poller" in KEMBA
+func Explain(tag string) Explanation {
+	registry.mu.Lock()
+	sources := registry.sources(nil)
+	registry.mu.Unlock()
+
+	return explain(tag, sources)
+}
+
+// explain will test every skip term before any include term, as a skip always wins,
+// and report the first term that matches.
+func explain(tag string, sources []patternSource) Explanation {
+	mode := getMatchModeFromEnv()
+	e := Explanation{Tag: tag}
+
+	for _, skip := range []bool{true, false} {
+		for _, src := range sources {
+			if src.patterns == "" {
+				continue
+			}
+
+			for _, term := range strings.Split(src.patterns, ",") {
+				if strings.HasPrefix(term, "-") != skip {
+					continue
+				}
+
+				if compileTerm(strings.TrimPrefix(term, "-"), mode).match(tag) {
+					e.Enabled = !skip
+					e.Source = src.name
+					e.Term = term
+					return e
+				}
+			}
+		}
+	}
+
+	return e
+}
+
+// explainEnabled reports if the KEMBA_EXPLAIN env value, or the configured Env.Explain, requests an explanation of every logger at creation.
+func explainEnabled() bool {
+	switch strings.ToLower(getenv(currentEnv().Explain)) {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
+
+// fields returns the explanation as the fields of a log event.
+func (e Explanation) fields() []field {
+	fields := []field{{key: "tag", value: e.Tag}, {key: "enabled", value: e.Enabled}}
+	if e.Term != "" {
+		fields = append(fields, field{key: "source", value: e.Source}, field{key: "term", value: e.Term})
+	}
+	return fields
+}
+
+// printExplanation will write the explanation of the logger to its writer, as a log event of
+// the kemba namespace when the format is JSON.
+func (k *core) printExplanation(e Explanation) {
+	k.mu.RLock()
+	out, format, now := k.out, k.format, k.clock.Now()
+	k.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if format == FormatJSON {
+		formatJSON(&buf, &record{time: now, tag: "kemba", lines: []string{e.String()}, fields: e.fields()})
+	} else {
+		_, _ = fmt.Fprintf(&buf, "kemba: %s\n", e)
+	}
+
+	k.write(out, buf.Bytes())
+}
@@ -0,0 +1,110 @@
+package kemba
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Explain(t *testing.T) {
+	is := assert.New(t)
+	defer registry.set(nil)
+
+	t.Run("should report the matching term and env var", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "other,app:*")
+		_ = os.Setenv("KEMBA", "-app:poller")
+
+		e := Explain("app:db")
+		is.Equal(Explanation{Tag: "app:db", Enabled: true, Source: "DEBUG", Term: "app:*"}, e)
+		is.Equal(`app:db enabled by "app:*" in DEBUG`, e.String())
+
+		_ = os.Setenv("DEBUG", "")
+		_ = os.Setenv("KEMBA", "")
+	})
+
+	t.Run("should report the blocking skip term", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "app:*")
+		_ = os.Setenv("KEMBA", "-app:poller")
+
+		e := Explain("app:poller")
+		is.Equal(Explanation{Tag: "app:poller", Enabled: false, Source: "KEMBA", Term: "-app:poller"}, e)
+		is.Equal(`app:poller disabled by "-app:poller" in KEMBA`, e.String())
+
+		_ = os.Setenv("DEBUG", "")
+		_ = os.Setenv("KEMBA", "")
+	})
+
+	t.Run("should report when no term matched", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "app:*")
+
+		e := Explain("other")
+		is.False(e.Enabled)
+		is.Equal("", e.Term)
+		is.Equal("other disabled: no term matched", e.String())
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should report patterns set with Enable", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "app:*")
+		Enable("app:db")
+
+		e := Explain("app:db")
+		is.Equal(Explanation{Tag: "app:db", Enabled: true, Source: "Enable", Term: "app:db"}, e)
+		is.Equal(Enabled("app:db"), e.Enabled)
+
+		_ = os.Setenv("DEBUG", "")
+	})
+}
+
+func Test_KEMBA_EXPLAIN(t *testing.T) {
+	is := assert.New(t)
+
+	explainTags := func() {
+		_ = os.Setenv("KEMBA_EXPLAIN", "1")
+		t.Cleanup(func() {
+			_ = os.Setenv("KEMBA_EXPLAIN", "")
+
+			registry.mu.Lock()
+			registry.explained = nil
+			registry.mu.Unlock()
+		})
+	}
+
+	t.Run("should print the decision once per logger at creation", func(t *testing.T) {
+		explainTags()
+
+		var buf bytes.Buffer
+		k := NewWithOptions("app:db", WithWriter(&buf), WithAllowed("app:*,-app:db"), WithColor(false))
+		k.Printf("hidden")
+		k.Printf("hidden")
+
+		is.Equal("kemba: app:db disabled by \"-app:db\" in WithAllowed\n", buf.String())
+	})
+
+	t.Run("should print the decision once per tag", func(t *testing.T) {
+		explainTags()
+
+		var buf bytes.Buffer
+		for i := 0; i < 3; i++ {
+			k := NewWithOptions("app:http", WithWriter(&buf), WithAllowed("app:**"), WithColor(false), WithTime(TimeNone))
+			_ = k.With("req", i)
+			_ = k.Extend("client")
+			_ = k.Handler().WithAttrs([]slog.Attr{slog.Int("req", i)})
+		}
+
+		is.Equal("kemba: app:http enabled by \"app:**\" in WithAllowed\nkemba: app:http:client enabled by \"app:**\" in WithAllowed\n", buf.String())
+	})
+
+	t.Run("should print the decision in the format of the logger", func(t *testing.T) {
+		explainTags()
+
+		var buf bytes.Buffer
+		_ = NewWithOptions("app:json", WithWriter(&buf), WithAllowed("app:*"), WithFormat(FormatJSON))
+
+		is.Regexp(`^\{"time":"[^"]+","ns":"kemba","msg":"app:json enabled by \\"app:\*\\" in WithAllowed","delta_ms":0,"fields":\{"enabled":true,"source":"WithAllowed","tag":"app:json","term":"app:\*"\}\}\n$`, buf.String())
+	})
+}
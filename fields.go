@@ -0,0 +1,111 @@
+package kemba
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// badKey is used as the key of a value passed without a matching key.
+const badKey = "!BADKEY"
+
+// field is a key/value pair attached to a log event.
+type field struct {
+	key   string
+	value interface{}
+}
+
+// With returns a new Kemba logger instance with the same tag that will render the provided
+// key/value pairs after the message of every log event.
+//
+// The key/value pairs are alternating keys and values. Keys are converted to strings, and a
+// value without a matching key is rendered with the "!BADKEY" key.
+//
+// The returned logger shares the state of the original logger, including its time delta.
+//
+// Example:
+//
+//	k := New("test:original")
+//	kw := k.With("id", 1337, "status", "ok")
+//	kw.Log("done")
+//
+// Output:
+//
+//	test:original done id=1337 status=ok
+func (k *Kemba) With(kv ...interface{}) *Kemba {
+	return &Kemba{core: k.core, fields: appendFields(k.fields, kv)}
+}
+
+// Logw will log the message followed by the fields of the logger and the provided key/value pairs.
+//
+// Unlike Printf and Println, the message is not passed through pretty.Formatter.
+func (k *Kemba) Logw(msg string, kv ...interface{}) {
+	if flight.active.Load() {
+		k.recordLines(messageLines(msg), appendFields(k.fields, kv))
+	}
+
+	if k.isEnabled() {
+		if t := k.testingTB(); t != nil {
+			t.Helper()
+		}
+		k.log(messageLines(msg), appendFields(k.fields, kv))
+	}
+}
+
+// messageLines will split the message into lines. An empty message is a single empty line,
+// so that the fields of the log event are still rendered.
+func messageLines(msg string) []string {
+	lines := scanLines(strings.NewReader(msg))
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	return lines
+}
+
+// appendFields will return a new slice with the key/value pairs appended to the fields.
+func appendFields(fields []field, kv []interface{}) []field {
+	out := make([]field, 0, len(fields)+(len(kv)+1)/2)
+	out = append(out, fields...)
+
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			out = append(out, field{key: badKey, value: kv[i]})
+			break
+		}
+
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		out = append(out, field{key: key, value: kv[i+1]})
+	}
+
+	return out
+}
+
+// formatFields will render the fields as space delimited key=value pairs, with a leading space.
+// Keys are rendered in the color of the tag when colors are enabled.
+func (k *Kemba) formatFields(fields []field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(" ")
+		sb.WriteString(paint(k.level, *PickColor(k.tag), f.key))
+		sb.WriteString("=")
+		sb.WriteString(formatValue(f.value))
+	}
+
+	return sb.String()
+}
+
+// formatValue will render a field value, quoting strings that would be ambiguous in the output.
+func formatValue(v interface{}) string {
+	s := fmt.Sprintf("%+v", v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
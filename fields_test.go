@@ -0,0 +1,105 @@
+package kemba
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_With(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should render fields after the message", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		kw := k.With("id", 1337, "status", "ok")
+		kw.Printf("key: %s", "test")
+
+		is.Regexp(`^test:kemba key: test id=1337 status=ok \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should not modify the original logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		_ = k.With("id", 1337)
+		k.Printf("key: %s", "test")
+
+		is.Regexp(`^test:kemba key: test \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should accumulate fields and carry them to extended loggers", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		ke := k.With("id", 1).With("user", "walrus").Extend("1")
+		ke.Println("test")
+
+		is.Regexp(`^test:kemba:1 test id=1 user=walrus \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should only render fields on the first line", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		k.With("id", 1).Printf("this\nis\nmultiline")
+
+		is.Regexp(`^test:kemba this id=1 \+\d+\S+\ntest:kemba is\ntest:kemba multiline\n$`, buf.String())
+	})
+
+	t.Run("should color keys when colors are enabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(true))
+		k.With("id", 1).Printf("test")
+
+		is.Contains(buf.String(), "\x1b[38;5;81mid\x1b[0m=1")
+	})
+}
+
+func Test_Logw(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should render the message and key/value pairs", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		k.With("id", 1).Logw("request done", "status", 200, "err", errors.New("not found"))
+
+		is.Regexp(`^test:kemba request done id=1 status=200 err="not found" \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should render a value without a key", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		k.Logw("done", "status", 200, "dangling")
+
+		is.Regexp(`^test:kemba done status=200 !BADKEY=dangling \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should render the fields of an empty message", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		k.Logw("", "id", 1)
+
+		is.Regexp(`^test:kemba  id=1 \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should do nothing when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed(""))
+		k.Logw("done", "status", 200)
+
+		is.Equal("", buf.String())
+	})
+}
+
+func Test_Private_formatValue(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should quote ambiguous strings", func(t *testing.T) {
+		is.Equal("ok", formatValue("ok"))
+		is.Equal(`"not ok"`, formatValue("not ok"))
+		is.Equal(`"a=b"`, formatValue("a=b"))
+		is.Equal(`""`, formatValue(""))
+		is.Equal("1337", formatValue(1337))
+		is.Equal(`"{a:1 b:2}"`, formatValue(struct{ a, b int }{1, 2}))
+	})
+}
@@ -0,0 +1,294 @@
+package kemba
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kr/pretty"
+)
+
+// flightRecorder holds the last log events of every logger, including disabled loggers.
+type flightRecorder struct {
+	active atomic.Bool
+	mu     sync.Mutex
+	events []flightEvent
+	next   int
+	full   bool
+}
+
+// flightKind is the logging method that recorded an event, which determines how it is formatted.
+type flightKind int
+
+const (
+	flightPrintf flightKind = iota
+	flightPrintln
+	flightLines
+)
+
+// flightEvent is a log event of the flight recorder. The messages of Printf and Println are
+// formatted when they are dumped, from a copy of their operands, unless an operand is mutable.
+// The lines of any other event are formatted when it is recorded.
+type flightEvent struct {
+	time   time.Time
+	tag    string
+	kind   flightKind
+	format string
+	args   []interface{}
+	lines  []string
+	fields []field
+}
+
+var flight = &flightRecorder{}
+
+// StartFlightRecorder will record the last size log events of every logger into a ring buffer,
+// including the events of disabled loggers, so that they can be written with Dump when something
+// fails. Events recorded before are dropped, and a size lower than 1 stops recording.
+//
+// Recording is cheap, as messages whose operands are strings and scalars are only formatted when
+// they are dumped. Any other operand or field value is formatted when it is logged, so that the
+// recorder never holds a reference to the values of the caller.
+//
+// Example:
+//
+//	kemba.StartFlightRecorder(1000)
+//	defer kemba.DumpOnPanic(os.Stderr)
+func StartFlightRecorder(size int) {
+	flight.mu.Lock()
+	defer flight.mu.Unlock()
+
+	flight.events, flight.next, flight.full = nil, 0, false
+	if size < 1 {
+		flight.active.Store(false)
+		return
+	}
+
+	flight.events = make([]flightEvent, size)
+	flight.active.Store(true)
+}
+
+// StopFlightRecorder will stop recording log events. The recorded events can still be dumped.
+func StopFlightRecorder() {
+	flight.active.Store(false)
+}
+
+// Dump will write the log events held by the flight recorder, oldest first, as text prefixed
+// with the ISO timestamp and the tag of each event.
+func Dump(w io.Writer) error {
+	var buf bytes.Buffer
+	for _, e := range flight.snapshot() {
+		e.writeText(&buf)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// DumpOnPanic will write the log events held by the flight recorder when the function deferring
+// it panics, then resume panicking. It must be deferred directly.
+//
+// Example:
+//
+//	defer kemba.DumpOnPanic(os.Stderr)
+func DumpOnPanic(w io.Writer) {
+	if r := recover(); r != nil {
+		_ = Dump(w)
+		panic(r)
+	}
+}
+
+// DumpOnError will write the log events held by the flight recorder when the error is not nil.
+// Deferred with a pointer to a named result, it dumps when the function returns an error.
+//
+// Example:
+//
+//	func run() (err error) {
+//		defer kemba.DumpOnError(os.Stderr, &err)
+//		...
+//	}
+func DumpOnError(w io.Writer, err *error) {
+	if err != nil && *err != nil {
+		_ = Dump(w)
+	}
+}
+
+// record will add a log event of Printf or Println to the flight recorder. The message is formatted
+// right away when an operand is mutable.
+func (k *Kemba) record(kind flightKind, format string, args []interface{}) {
+	if k.core == nil {
+		return
+	}
+	k.sync()
+
+	k.mu.RLock()
+	now := k.clock.Now()
+	k.mu.RUnlock()
+
+	e := flightEvent{time: now, tag: k.tag, kind: kind, format: format, fields: freezeFields(k.fields)}
+	if frozen, ok := freezeArgs(args); ok {
+		e.args = frozen
+	} else {
+		e.args = args
+		e.lines, _ = e.message()
+		e.kind, e.format, e.args = flightLines, "", nil
+	}
+
+	flight.add(e)
+}
+
+// recordLines will add a log event with formatted lines to the flight recorder.
+func (k *Kemba) recordLines(lines []string, fields []field) {
+	if k.core == nil {
+		return
+	}
+	k.sync()
+
+	k.mu.RLock()
+	now := k.clock.Now()
+	k.mu.RUnlock()
+
+	flight.add(flightEvent{time: now, tag: k.tag, kind: flightLines, lines: lines, fields: freezeFields(fields)})
+}
+
+// recordSpan will record the start of a span of a disabled logger to the flight recorder, and
+// return the function recording its duration.
+func (k *Kemba) recordSpan(label string) func() {
+	k.sync()
+
+	k.mu.RLock()
+	start := k.clock.Now()
+	k.mu.RUnlock()
+
+	k.recordLines([]string{label + "..."}, k.fields)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if !flight.active.Load() {
+				return
+			}
+
+			k.mu.RLock()
+			d := k.clock.Now().Sub(start)
+			k.mu.RUnlock()
+
+			k.recordLines([]string{fmt.Sprintf("%s took %s", label, d)}, k.fields)
+		})
+	}
+}
+
+// freezeValue returns a copy of strings and scalars, which can be formatted later without reading
+// memory of the caller. It reports false for any other value.
+func freezeValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128, time.Duration, time.Time:
+		return v, true
+	case string:
+		return strings.Clone(v), true
+	}
+	return nil, false
+}
+
+// freezeArgs returns a copy of the operands, or reports false when one of them is mutable.
+func freezeArgs(args []interface{}) ([]interface{}, bool) {
+	frozen := make([]interface{}, len(args))
+	for i, a := range args {
+		v, ok := freezeValue(a)
+		if !ok {
+			return nil, false
+		}
+		frozen[i] = v
+	}
+	return frozen, true
+}
+
+// freezeFields returns a copy of the fields, with the values that are not strings or scalars
+// formatted as they are rendered.
+func freezeFields(fields []field) []field {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	frozen := make([]field, len(fields))
+	for i, f := range fields {
+		v, ok := freezeValue(f.value)
+		if !ok {
+			v = fmt.Sprintf("%+v", f.value)
+		}
+		frozen[i] = field{key: f.key, value: v}
+	}
+	return frozen
+}
+
+// add will store the event, overwriting the oldest event when the buffer is full.
+func (f *flightRecorder) add(e flightEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.events) == 0 {
+		return
+	}
+
+	f.events[f.next] = e
+	f.next = (f.next + 1) % len(f.events)
+	if f.next == 0 {
+		f.full = true
+	}
+}
+
+// snapshot returns the stored events, oldest first.
+func (f *flightRecorder) snapshot() []flightEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.full {
+		return append([]flightEvent(nil), f.events[:f.next]...)
+	}
+	return append(append([]flightEvent(nil), f.events[f.next:]...), f.events[:f.next]...)
+}
+
+// message will format the lines and fields of the event, as the logging method that recorded it.
+func (e flightEvent) message() ([]string, []field) {
+	switch e.kind {
+	case flightPrintln:
+		return formatOperands(e.args), e.fields
+	case flightLines:
+		return e.lines, e.fields
+	default:
+		var buf bytes.Buffer
+		_, _ = pretty.Fprintf(&buf, e.format, e.args...)
+		return scanLines(&buf), e.fields
+	}
+}
+
+// writeText will render the event as text lines without colors, with the fields on the first line.
+func (e flightEvent) writeText(buf *bytes.Buffer) {
+	ts := TimeISO.formatTimestamp(e.time)
+
+	lines, fields := e.message()
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	for i, ln := range lines {
+		buf.WriteString(ts)
+		buf.WriteString(" ")
+		buf.WriteString(e.tag)
+		buf.WriteString(" ")
+		buf.WriteString(ln)
+		if i == 0 {
+			for _, f := range fields {
+				buf.WriteString(" ")
+				buf.WriteString(f.key)
+				buf.WriteString("=")
+				buf.WriteString(formatValue(f.value))
+			}
+		}
+		buf.WriteString("\n")
+	}
+}
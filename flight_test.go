@@ -0,0 +1,203 @@
+package kemba
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Dump(t *testing.T) {
+	is := assert.New(t)
+
+	newRecorded := func() *Kemba {
+		now := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+		clock := ClockFunc(func() time.Time {
+			now = now.Add(time.Millisecond)
+			return now
+		})
+		return NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed(""), WithClock(clock))
+	}
+
+	t.Run("should dump the events of disabled loggers", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		k.Printf("key: %s", "test")
+		k.With("id", 1).Println("a string", 12)
+		k.Logw("done", "status", "not ok")
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal(`2020-07-27T00:00:00.001Z test:kemba key: test
+2020-07-27T00:00:00.002Z test:kemba a string id=1
+2020-07-27T00:00:00.002Z test:kemba int(12)
+2020-07-27T00:00:00.003Z test:kemba done status="not ok"
+`, buf.String())
+	})
+
+	t.Run("should only keep the last events", func(t *testing.T) {
+		StartFlightRecorder(2)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		for i := 1; i <= 5; i++ {
+			k.Printf("event %d", i)
+		}
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal("2020-07-27T00:00:00.004Z test:kemba event 4\n2020-07-27T00:00:00.005Z test:kemba event 5\n", buf.String())
+	})
+
+	t.Run("should format mutable values when they are logged", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		v := []int{1}
+		m := map[string]int{"a": 1}
+		k.Printf("%v", v)
+		k.With("m", m).Logw("done", "v", v)
+		v[0], m["a"] = 2, 2
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal("2020-07-27T00:00:00.001Z test:kemba [1]\n2020-07-27T00:00:00.002Z test:kemba done m=map[a:1] v=[1]\n", buf.String())
+	})
+
+	t.Run("should not share the operands with the caller", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		args := []interface{}{"a", 1}
+		k.Printf("%s %d", args...)
+		args[0], args[1] = "b", 2
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal("2020-07-27T00:00:00.001Z test:kemba a 1\n", buf.String())
+	})
+
+	t.Run("should dump while the logged values are modified", func(t *testing.T) {
+		StartFlightRecorder(100)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		v := []int{0}
+		k.Printf("%v", v)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 1; i <= 100; i++ {
+				v[0] = i
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			is.NoError(Dump(&bytes.Buffer{}))
+		}
+		<-done
+	})
+
+	t.Run("should record the writers, spans and handler of disabled loggers", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		k := newRecorded()
+		w := k.Writer()
+		_, _ = w.Write([]byte("line 1\nline"))
+		is.NoError(w.Close())
+		k.StdLogger().Print("std")
+		k.Time("span")()
+		slog.New(k.Handler()).Debug("slog", "id", 1)
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal(`2020-07-27T00:00:00.001Z test:kemba line 1
+2020-07-27T00:00:00.002Z test:kemba line
+2020-07-27T00:00:00.003Z test:kemba std
+2020-07-27T00:00:00.005Z test:kemba span...
+2020-07-27T00:00:00.007Z test:kemba span took 2ms
+2020-07-27T00:00:00.008Z test:kemba slog level=DEBUG id=1
+`, buf.String())
+	})
+
+	t.Run("should not record when stopped", func(t *testing.T) {
+		StartFlightRecorder(10)
+		StopFlightRecorder()
+		defer StartFlightRecorder(0)
+
+		newRecorded().Printf("test")
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal("", buf.String())
+	})
+}
+
+func Test_DumpOnPanic(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should dump and resume panicking", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		var buf bytes.Buffer
+		is.PanicsWithValue("boom", func() {
+			defer DumpOnPanic(&buf)
+			NewWithOptions("test:kemba", WithAllowed("")).Printf("before")
+			panic("boom")
+		})
+		is.Contains(buf.String(), "test:kemba before\n")
+	})
+
+	t.Run("should not dump without a panic", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		var buf bytes.Buffer
+		func() {
+			defer DumpOnPanic(&buf)
+			NewWithOptions("test:kemba", WithAllowed("")).Printf("before")
+		}()
+		is.Equal("", buf.String())
+	})
+}
+
+func Test_DumpOnError(t *testing.T) {
+	is := assert.New(t)
+
+	run := func(buf *bytes.Buffer, fail bool) (err error) {
+		defer DumpOnError(buf, &err)
+		NewWithOptions("test:kemba", WithAllowed("")).Printf("before")
+		if fail {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	t.Run("should dump when the function fails", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		var buf bytes.Buffer
+		is.Error(run(&buf, true))
+		is.Contains(buf.String(), "test:kemba before\n")
+	})
+
+	t.Run("should not dump when the function succeeds", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		var buf bytes.Buffer
+		is.NoError(run(&buf, false))
+		is.Equal("", buf.String())
+	})
+}
@@ -0,0 +1,113 @@
+package kemba
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is the output format of a Kemba logger.
+type Format int
+
+const (
+	// FormatText outputs each log event as lines prefixed with the tag, followed by the time delta.
+	FormatText Format = iota
+	// FormatJSON outputs each log event as a single line JSON object.
+	FormatJSON
+)
+
+// record is a single log event.
+type record struct {
+	time   time.Time
+	tag    string
+	lines  []string
+	delta  time.Duration
+	fields []field
+	caller string
+}
+
+// msg returns the lines of the log event as a single string.
+func (r *record) msg() string {
+	return strings.Join(r.lines, "\n")
+}
+
+// title returns the first line of the message.
+func (r *record) title() string {
+	if len(r.lines) == 0 {
+		return ""
+	}
+	return r.lines[0]
+}
+
+// jsonRecord is the JSON representation of a log event.
+type jsonRecord struct {
+	Time    string                 `json:"time"`
+	NS      string                 `json:"ns"`
+	Msg     string                 `json:"msg"`
+	DeltaMS int64                  `json:"delta_ms"`
+	Caller  string                 `json:"caller,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// formatJSON will render the log event as a single line JSON object.
+func formatJSON(buf *bytes.Buffer, r *record) {
+	jr := jsonRecord{
+		Time:    r.time.Format(time.RFC3339Nano),
+		NS:      r.tag,
+		Msg:     r.msg(),
+		DeltaMS: r.delta.Milliseconds(),
+		Caller:  r.caller,
+	}
+
+	if len(r.fields) > 0 {
+		jr.Fields = make(map[string]interface{}, len(r.fields))
+		for _, f := range r.fields {
+			jr.Fields[f.key] = jsonValue(f.value)
+		}
+	}
+
+	b, _ := json.Marshal(jr)
+	buf.Write(b)
+	buf.WriteString("\n")
+}
+
+// jsonValue will convert field values that do not have a useful JSON representation.
+// Values that can not be marshaled, such as functions and channels, fall back to their
+// string representation.
+func jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		if _, ok := v.(json.Marshaler); !ok {
+			return x.String()
+		}
+	}
+
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return v
+}
+
+// scanLines will split the output of the reader into lines.
+func scanLines(r io.Reader) []string {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+// getFormatFromEnv will read the output format from the KEMBA_FORMAT env value, or the configured Env.Format.
+func getFormatFromEnv() Format {
+	if strings.EqualFold(getenv(currentEnv().Format), "json") {
+		return FormatJSON
+	}
+	return FormatText
+}
@@ -0,0 +1,69 @@
+package kemba
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FormatJSON(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should output a single JSON object per call", func(t *testing.T) {
+		now := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithFormat(FormatJSON), WithClock(ClockFunc(clock)))
+		now = now.Add(12 * time.Millisecond)
+
+		type myType struct {
+			a, b int
+		}
+		k.Printf("%# v", []myType{{1, 2}, {3, 4}})
+
+		is.Equal(`{"time":"2020-07-27T00:00:00.012Z","ns":"test:kemba","msg":"[]kemba.myType{\n    {a:1, b:2},\n    {a:3, b:4},\n}","delta_ms":12}`+"\n", buf.String())
+	})
+
+	t.Run("should join Println operands in the message", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithFormat(FormatJSON))
+		k.Println("a string", 12, true)
+
+		var out map[string]interface{}
+		is.NoError(json.Unmarshal(buf.Bytes(), &out))
+		is.Equal("a string\nint(12)\nbool(true)", out["msg"])
+		is.Equal(1, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("should preserve fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithFormat(FormatJSON))
+		k.With("id", 1337).Logw("done", "err", errors.New("not found"), "fn", func() {})
+
+		var out map[string]interface{}
+		is.NoError(json.Unmarshal(buf.Bytes(), &out))
+		is.Equal("done", out["msg"])
+		is.Equal("test:kemba", out["ns"])
+		is.Equal(map[string]interface{}{"id": float64(1337), "err": "not found", "fn": out["fields"].(map[string]interface{})["fn"]}, out["fields"])
+		is.Contains(out["fields"].(map[string]interface{})["fn"], "0x")
+	})
+
+	t.Run("should read the format from KEMBA_FORMAT", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_FORMAT", "json")
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"))
+		k.Printf("test")
+
+		is.Regexp(`^\{"time":"[^"]+","ns":"test:kemba","msg":"test","delta_ms":\d+\}\n$`, buf.String())
+
+		_ = os.Setenv("KEMBA_FORMAT", "")
+	})
+}
@@ -0,0 +1,93 @@
+package kemba
+
+import (
+	"strings"
+	"sync"
+)
+
+// TB is the part of testing.TB used by ForTest, so that the package does not import testing.
+type TB interface {
+	Helper()
+	Log(args ...interface{})
+	Cleanup(f func())
+}
+
+// tbWriter is an io.Writer logging each log event with t.Log, until the test completes.
+type tbWriter struct {
+	t    TB
+	mu   sync.RWMutex
+	done bool
+}
+
+// Write will log the log event without its trailing newline. Events written after the test
+// completed are dropped, as calling t.Log would panic.
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.done {
+		w.t.Helper()
+		w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// close will drop the events written afterwards, once the writes in progress are done.
+func (w *tbWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.done = true
+}
+
+// ForTest will enable the patterns and route the output of every logger that was not created with
+// WithWriter through t.Log until the test completes, so that the log events of the code under test
+// are attached to the output of the test. This includes loggers created before the call.
+//
+// The logging methods are marked as test helpers, so t.Log reports the location of the log call.
+// Loggers are global, so ForTest must not be used by tests running in parallel.
+//
+// Example:
+//
+//	func TestSync(t *testing.T) {
+//		kemba.ForTest(t, "app:sync:*")
+//		...
+//	}
+func ForTest(t TB, patterns string) {
+	t.Helper()
+
+	w := &tbWriter{t: t}
+	restore := Redirect(patterns, w, getFormatFromEnv())
+	t.Cleanup(func() {
+		restore()
+		w.close()
+	})
+}
+
+// testingTB returns the test the logger writes to with ForTest, or nil.
+//
+// The log call must mark itself and every function between it and the write as test helpers, with:
+//
+//	if t := k.testingTB(); t != nil {
+//		t.Helper()
+//	}
+func (k *Kemba) testingTB() TB {
+	if k.core == nil {
+		return nil
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.outputTB()
+}
+
+// outputTB returns the test the logger writes to with ForTest, or nil.
+//
+// The caller must hold the lock.
+func (k *Kemba) outputTB() TB {
+	if w, ok := k.out.(*tbWriter); ok {
+		return w.t
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package kemba
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records the log calls, the functions marked as helpers and the cleanup functions.
+type fakeTB struct {
+	testing.TB
+	helpers  map[string]bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeTB) Helper() {
+	pc, _, _, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	t.helpers[name[strings.LastIndex(name, ".")+1:]] = true
+}
+
+func (t *fakeTB) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *fakeTB) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func Test_ForTest(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should route existing loggers to the test", func(t *testing.T) {
+		k := New("test:fortest")
+		tb := &fakeTB{helpers: map[string]bool{}}
+
+		ForTest(tb, "test:*")
+		k.Printf("first\nsecond")
+		k.Logw("test", "id", 1)
+
+		is.Len(tb.logs, 2)
+		is.Regexp(`^test:fortest first \+\S+\ntest:fortest second$`, tb.logs[0])
+		is.Regexp(`^test:fortest test id=1 \+\S+$`, tb.logs[1])
+
+		for _, name := range []string{"Printf", "Logw", "log", "logPC", "Write"} {
+			is.True(tb.helpers[name], name)
+		}
+
+		is.Len(tb.cleanups, 1)
+		tb.cleanups[0]()
+		is.False(k.isEnabled())
+	})
+
+	t.Run("should drop the events written once the test completed", func(t *testing.T) {
+		k := New("test:fortest")
+		tb := &fakeTB{helpers: map[string]bool{}}
+
+		ForTest(tb, "test:*")
+		is.True(k.isEnabled())
+
+		k.mu.RLock()
+		out := k.out
+		k.mu.RUnlock()
+
+		tb.cleanups[0]()
+		_, _ = out.Write([]byte("late\n"))
+
+		is.Empty(tb.logs)
+	})
+
+	t.Run("should not import the testing package", func(t *testing.T) {
+		out, err := exec.Command("go", "list", "-deps", ".").Output()
+		is.NoError(err)
+		is.NotContains(strings.Fields(string(out)), "testing")
+	})
+
+	t.Run("should attach the output to the test", func(t *testing.T) {
+		ForTest(t, "test:*")
+		New("test:fortest").Printf("visible with go test -v")
+	})
+}
@@ -1,158 +1,220 @@
 package kemba
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"github.com/gookit/color"
 	"github.com/kr/pretty"
-	"hash/crc64"
-	"log"
-	"math/rand"
+	"io"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Kemba is a container struct for the Kemba logger.
 // It used to manage the state of the logger.
 // Currently all properties are not exported.
+//
+// A Kemba logger is safe for concurrent use by multiple goroutines. Each log event is written
+// with a single write, so the lines of multi-line values are never interleaved.
 type Kemba struct {
+	*core
+	fields []field
+}
+
+// core is the state of a logger for a tag. It is shared by the loggers derived with With and
+// Handler.WithAttrs.
+type core struct {
+	mu      sync.RWMutex
 	tag     string
 	allowed string
 	enabled bool
-	logger  *log.Logger
+	prefix  string
 	color   bool
+	level   ColorLevel
+	out     io.Writer
+	format  Format
+	caller  Caller
+	time    TimeMode
 	last    time.Time
+	clock   Clock
+	cfg     *config
+	opts    []Option
+	spans   spans
+	gen     uint64
+
+	// writeMu serializes the writes of the logger. It is only held while writing, and not with
+	// mu, so that a writer may log through another logger.
+	writeMu sync.Mutex
 }
 
 var (
-	table  = crc64.MakeTable(crc64.ISO)
-	gs     = color.C256(uint8(240))
-	colors = []int{
-		20,
-		21,
-		26,
-		27,
-		32,
-		33,
-		38,
-		39,
-		40,
-		41,
-		42,
-		43,
-		44,
-		45,
-		56,
-		57,
-		62,
-		63,
-		68,
-		69,
-		74,
-		75,
-		76,
-		77,
-		78,
-		79,
-		80,
-		81,
-		92,
-		93,
-		98,
-		99,
-		112,
-		113,
-		128,
-		129,
-		134,
-		135,
-		148,
-		149,
-		160,
-		161,
-		162,
-		163,
-		164,
-		165,
-		166,
-		167,
-		168,
-		169,
-		170,
-		171,
-		172,
-		173,
-		178,
-		179,
-		184,
-		185,
-		196,
-		197,
-		198,
-		199,
-		200,
-		201,
-		202,
-		203,
-		204,
-		205,
-		206,
-		207,
-		208,
-		209,
-		214,
-		215,
-		220,
-		221,
-	}
+	// pickedColors caches the color picked for each tag.
+	pickedColors sync.Map
+
+	gs = color.C256(uint8(240))
 )
 
 // New Returns a Kemba logging instance. It will determine if the logger should
 // bypass logging actions or be activated.
+//
+// Loggers are re-evaluated when Enable, Disable or SetEnv change the patterns, and are not
+// retained once they are no longer referenced.
 func New(tag string) *Kemba {
-	allowed := getDebugFlagFromEnv()
+	return NewWithOptions(tag)
+}
+
+// NewWithOptions Returns a Kemba logging instance configured with the provided options.
+// Any setting not provided by an option falls back to the environment, as with New.
+func NewWithOptions(tag string, opts ...Option) *Kemba {
+	return newLogger(tag, opts, nil)
+}
+
+// newLogger will create a Kemba logger carrying the provided fields.
+func newLogger(tag string, opts []Option, fields []field) *Kemba {
+	return &Kemba{core: newCore(tag, opts), fields: fields}
+}
+
+// newCore will create and evaluate the state of a logger for the tag.
+func newCore(tag string, opts []Option) *core {
+	c := &core{tag: tag, cfg: newConfig(opts), opts: opts}
+	registry.add(c)
+
+	return c
+}
 
-	logger := Kemba{tag: tag, allowed: allowed}
+// sync will re-evaluate the logger when the patterns, the output or the clock changed since it
+// was last evaluated.
+func (k *core) sync() {
+	gen := registry.gen.Load()
 
-	if logger.allowed != "" {
-		logger.enabled = determineEnabled(tag, allowed)
-		logger.color = os.Getenv("NOCOLOR") == ""
+	k.mu.RLock()
+	current := k.gen == gen
+	k.mu.RUnlock()
+	if current {
+		return
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.gen != registry.gen.Load() {
+		registry.evaluate(k)
+	}
+}
+
+// update will re-evaluate if the logger is enabled for the provided allowed string and
+// prepare the underlying logger when it is. Loggers created without WithWriter write to the
+// redirect when one is provided, and loggers created without WithClock read the provided clock.
+// The format, caller and time modes not provided by an option are read from the environment.
+//
+// The caller must hold the write lock.
+func (k *core) update(allowed string, rd *redirect, clock Clock) {
+	wasEnabled := k.enabled
+	k.allowed = allowed
+
+	k.clock = k.cfg.clock
+	if k.clock == nil {
+		k.clock = clock
+	}
+
+	k.out, k.format = k.cfg.writer, getFormatFromEnv()
+	if k.cfg.format != nil {
+		k.format = *k.cfg.format
+	}
+	if k.out == nil {
+		k.out = os.Stderr
+		if rd != nil {
+			k.out = rd.writer
+			if rd.format != nil {
+				k.format = *rd.format
+			}
+		}
+	}
+
+	k.caller = getCallerFromEnv()
+	if k.cfg.caller != nil {
+		k.caller = *k.cfg.caller
+	}
+
+	mode := getTimeModeFromEnv()
+	if k.cfg.time != nil {
+		mode = *k.cfg.time
+	}
+	k.time = resolveTimeMode(mode, k.out)
+
+	if k.allowed != "" {
+		k.enabled = determineEnabled(k.tag, k.allowed)
+		k.level = k.colorLevel()
 	} else {
-		logger.enabled = false
-		logger.color = false
+		k.enabled = false
+		k.level = ColorNone
 	}
+	k.color = k.level != ColorNone
 
-	var prefix string
-	if logger.enabled {
-		if logger.color {
-			s := PickColor(tag)
-			prefix = s.Sprintf("%s ", tag)
-		} else {
-			prefix = fmt.Sprintf("%s ", tag)
+	if k.enabled {
+		k.prefix = k.newPrefix()
+		if !wasEnabled {
+			k.last = k.clock.Now()
 		}
+	}
+}
+
+// colorLevel returns the color capability set with WithColorLevel or WithColor, or detected
+// for the writer.
+func (k *core) colorLevel() ColorLevel {
+	switch {
+	case k.cfg.level != nil:
+		return *k.cfg.level
+	case k.cfg.color == nil:
+		return detectColorLevel(k.out)
+	case *k.cfg.color:
+		return Color256
+	default:
+		return ColorNone
+	}
+}
+
+// newPrefix will create the tag prefix of text output, colored when colors are enabled.
+func (k *core) newPrefix() string {
+	return paint(k.level, *PickColor(k.tag), fmt.Sprintf("%s ", k.tag))
+}
 
-		logger.logger = log.New(os.Stderr, prefix, log.Lmsgprefix)
-		logger.last = time.Now()
+// isEnabled reports if the logger is enabled. The zero Kemba value has no state and is disabled.
+func (k *core) isEnabled() bool {
+	if k == nil {
+		return false
 	}
+	k.sync()
 
-	return &logger
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.enabled
 }
 
 // Printf is a convenience wrapper that will apply pretty.Formatter to the passed in variables.
 //
 // Calling Printf(f, x, y) is equivalent to fmt.Printf(f, pretty.Formatter(x), pretty.Formatter(y)).
 func (k *Kemba) Printf(format string, v ...interface{}) {
-	if k.enabled {
-		elapsed := k.determineElapsed()
+	if flight.active.Load() {
+		k.record(flightPrintf, format, v)
+	}
+
+	if k.isEnabled() {
+		if t := k.testingTB(); t != nil {
+			t.Helper()
+		}
 
 		var buf bytes.Buffer
 		_, _ = pretty.Fprintf(&buf, format, v...)
 
-		showDelta := true
-		k.printBuffer(buf, elapsed, &showDelta)
+		k.log(scanLines(&buf), k.fields)
 	}
 }
 
@@ -161,22 +223,42 @@ func (k *Kemba) Printf(format string, v ...interface{}) {
 // Calling Println(x, y) is equivalent to fmt.Println(pretty.Formatter(x), pretty.Formatter(y)),
 // but each operand is formatted with "%# v".
 func (k *Kemba) Println(v ...interface{}) {
-	if k.enabled {
-		showDelta := true
-		for _, x := range v {
-			elapsed := k.determineElapsed()
-
-			var buf bytes.Buffer
-			_, _ = pretty.Fprintf(&buf, "%# v", x)
+	if flight.active.Load() {
+		k.record(flightPrintln, "", v)
+	}
 
-			k.printBuffer(buf, elapsed, &showDelta)
+	if k.isEnabled() {
+		if t := k.testingTB(); t != nil {
+			t.Helper()
 		}
+		k.log(formatOperands(v), k.fields)
 	}
 }
 
 // Log is an alias to Println
 func (k *Kemba) Log(v ...interface{}) {
-	k.Println(v...)
+	if flight.active.Load() {
+		k.record(flightPrintln, "", v)
+	}
+
+	if k.isEnabled() {
+		if t := k.testingTB(); t != nil {
+			t.Helper()
+		}
+		k.log(formatOperands(v), k.fields)
+	}
+}
+
+// formatOperands will format each operand with "%# v" and return the resulting lines.
+func formatOperands(v []interface{}) []string {
+	var lines []string
+	for _, x := range v {
+		var buf bytes.Buffer
+		_, _ = pretty.Fprintf(&buf, "%# v", x)
+
+		lines = append(lines, scanLines(&buf)...)
+	}
+	return lines
 }
 
 // Extend returns a new Kemba logger instance that has appended the provided tag to the original logger.
@@ -194,101 +276,171 @@ func (k *Kemba) Log(v ...interface{}) {
 //
 //	test:original test
 //	test:original:plugin test extended
+//
+// The extended logger is created with the same options and fields as the original logger.
 func (k *Kemba) Extend(tag string) *Kemba {
-	exTag := fmt.Sprintf("%s:%s", k.tag, tag)
-	return New(exTag)
+	if k.core == nil {
+		return New(fmt.Sprintf(":%s", tag))
+	}
+	return newLogger(fmt.Sprintf("%s:%s", k.tag, tag), k.opts, k.fields)
 }
 
-// PickColor will return the same color based on input string.
-//
-// We want to pick the same color for a given tag to ensure consistent output behavior.
-func PickColor(tag string) *color.Color256 {
-	// Generate an 8 byte checksum to pass into Rand.seed
-	seed := crc64.Checksum([]byte(tag), table)
-	rand.Seed(int64(seed))
-	v := rand.Intn(len(colors) - 1)
-	s := color.C256(uint8(colors[v]))
-	return &s
-}
+// log will print the lines as a single log event, annotated with the caller of the public
+// logging method when enabled. It must be called directly by the public logging methods.
+func (k *Kemba) log(lines []string, fields []field) {
+	if t := k.testingTB(); t != nil {
+		t.Helper()
+	}
 
-// printBuffer will append the elapsed time delta to the first line of the provided buffer
-// if the showDelta parameter is true. Otherwise, this method prints the buffer lines to STDERR
-func (k *Kemba) printBuffer(buf bytes.Buffer, elapsed time.Duration, showDelta *bool) {
-	s := bufio.NewScanner(&buf)
-	for s.Scan() {
-		if *showDelta {
-			var ft string
-			if k.color {
-				ft = gs.Sprintf("+%s", elapsed.Truncate(time.Millisecond))
-			} else {
-				ft = fmt.Sprintf("+%s", elapsed.Truncate(time.Millisecond))
-			}
-			k.logger.Printf("%s %s\n", s.Text(), ft)
-			*showDelta = false
-		} else {
-			k.logger.Print(s.Text())
-		}
+	k.mu.RLock()
+	mode := k.caller
+	k.mu.RUnlock()
+
+	var pc uintptr
+	if mode != CallerNone {
+		pc = callerPC(callerDepth)
 	}
+
+	k.logPC(pc, lines, fields)
 }
 
-// getDebugFlagFromEnv considers both the value of DEBUG and KEMBA env values
-// to determine the resulting logging flags to pass to the loggers.
-func getDebugFlagFromEnv() string {
-	dEnv := os.Getenv("DEBUG")
-	kEnv := os.Getenv("KEMBA")
+// logPC will print the lines as a single log event, unless the logger was disabled while they were formatted.
+//
+// The event is formatted while the lock is held, so that the time deltas follow the order of the
+// events, and written once the lock is released.
+func (k *Kemba) logPC(pc uintptr, lines []string, fields []field) {
+	if k.core == nil {
+		return
+	}
+	k.sync()
 
-	var s []string
-	if dEnv != "" {
-		s = append(s, dEnv)
+	k.mu.Lock()
+	if t := k.outputTB(); t != nil {
+		t.Helper()
 	}
-	if kEnv != "" {
-		s = append(s, kEnv)
+
+	if !k.enabled {
+		k.mu.Unlock()
+		return
 	}
 
-	if len(s) > 1 {
-		return strings.Join(s, ",")
-	} else if len(s) > 0 {
-		return s[0]
+	r := k.newRecord(lines, fields)
+	r.caller = formatCaller(pc, k.caller)
+	b, out := k.render(r), k.out
+	k.mu.Unlock()
+
+	if len(b) > 0 {
+		k.write(out, b)
 	}
 
-	return ""
+	if trace.active.Load() {
+		trace.add(timelineEvent{tag: r.tag, name: r.title(), time: r.time, fields: r.fields})
+	}
 }
 
-// determineElapsed will determine the time delta from between the last log event for this
-// Kemba logger and return the elapsed time.
-func (k *Kemba) determineElapsed() time.Duration {
-	now := time.Now()
+// newRecord will create a log event for the provided lines, stamped with the current time
+// and the elapsed time since the last log event.
+//
+// The caller must hold the write lock.
+func (k *Kemba) newRecord(lines []string, fields []field) *record {
+	now := k.clock.Now()
 	elapsed := now.Sub(k.last)
 	k.last = now
 
-	return elapsed
+	return &record{
+		time:   now,
+		tag:    k.tag,
+		lines:  lines,
+		delta:  elapsed,
+		fields: fields,
+	}
 }
 
-// determineEnabled will check the value of DEBUG and KEMBA environment variables to generate regex to test against the tag
+// render will format the log event in the configured format.
 //
-// If no * in string, then assume exact match
-// Else
-// It will split by , and perform
-// It will, replace * with .*
-func determineEnabled(tag string, allowed string) bool {
-	var a bool
-	for _, l := range strings.Split(allowed, ",") {
-		if strings.Contains(l, "*") {
-			reg := strings.ReplaceAll(l, "*", ".*")
-			if !strings.HasPrefix(reg, "^") && !strings.HasPrefix(reg, "*") {
-				reg = fmt.Sprintf("^%s", reg)
-			}
+// The caller must hold the lock.
+func (k *Kemba) render(r *record) []byte {
+	var buf bytes.Buffer
+	if k.format == FormatJSON {
+		formatJSON(&buf, r)
+	} else {
+		k.formatText(&buf, r)
+	}
 
-			if !strings.HasSuffix(reg, "$") {
-				reg = fmt.Sprintf("%s$", reg)
-			}
+	return buf.Bytes()
+}
+
+// write will write the bytes of a log event to the writer, serialized with the other writes of the logger.
+func (k *core) write(out io.Writer, b []byte) {
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+
+	_, _ = out.Write(b)
+}
 
-			if !a {
-				a, _ = regexp.Match(reg, []byte(tag))
+// formatText will append the fields, the elapsed time delta and the caller to the first line of the
+// log event and prefix every line with the tag, preceded by the timestamp when enabled.
+func (k *Kemba) formatText(buf *bytes.Buffer, r *record) {
+	mode := k.time
+
+	ts := paint(k.level, gs, mode.formatTimestamp(r.time))
+
+	for i, ln := range r.lines {
+		if ts != "" {
+			buf.WriteString(ts)
+			buf.WriteString(" ")
+		}
+		buf.WriteString(k.prefix)
+		buf.WriteString(ln)
+		if i == 0 {
+			buf.WriteString(k.formatFields(r.fields))
+			if mode.showDelta() {
+				buf.WriteString(" ")
+				buf.WriteString(paint(k.level, gs, fmt.Sprintf("+%s", r.delta.Truncate(time.Millisecond))))
+			}
+			if r.caller != "" {
+				buf.WriteString(" ")
+				buf.WriteString(paint(k.level, gs, r.caller))
 			}
-		} else if !a {
-			a = l == tag
 		}
+		buf.WriteString("\n")
+	}
+}
+
+// getDebugFlagFromEnv considers both the value of DEBUG and KEMBA env values
+// to determine the resulting logging flags to pass to the loggers.
+//
+// The variables and how their values are combined can be configured with SetEnv.
+func getDebugFlagFromEnv() string {
+	var s []string
+	for _, src := range envSources() {
+		s = append(s, src.patterns)
+	}
+
+	return strings.Join(s, ",")
+}
+
+// determineEnabled will check the value of DEBUG and KEMBA environment variables to generate regex to test against the tag
+//
+// It will split by , and test each term with the wildcard semantics selected by KEMBA_MATCH.
+// The terms are compiled once per allowed string and cached.
+//
+// Terms prefixed with - are skips. A tag matching any skip term is never enabled, regardless of
+// the order of the terms or any other term matching it.
+func determineEnabled(tag string, allowed string) bool {
+	return compileMatcher(allowed, getMatchModeFromEnv()).Match(tag)
+}
+
+// legacyRegexp will convert a term into an anchored regular expression, quoting the literal parts.
+// A leading ^ and trailing $ are accepted as anchors for compatibility.
+func legacyRegexp(term string) string {
+	term = strings.TrimPrefix(term, "^")
+	term = strings.TrimSuffix(term, "$")
+
+	parts := strings.Split(term, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
 	}
-	return a
+
+	return fmt.Sprintf("^%s$", strings.Join(parts, ".*"))
 }
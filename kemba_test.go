@@ -2,15 +2,24 @@
 package kemba
 
 import (
+	"bytes"
 	"github.com/gookit/color"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
+	"log/slog"
+	"math/rand"
 	"os"
 	"strings"
 	"testing"
-	"time"
 )
 
+// TestMain pins the time mode to deltas, as the tests capture os.Stderr with pipes,
+// which would otherwise be detected as non-terminal output.
+func TestMain(m *testing.M) {
+	_ = os.Setenv("KEMBA_TIME", "delta")
+	os.Exit(m.Run())
+}
+
 func Test_New(t *testing.T) {
 	is := assert.New(t)
 
@@ -71,6 +80,20 @@ func Test_New(t *testing.T) {
 		is.True(k.enabled, "Logger should be enabled")
 	})
 
+	t.Run("fuzzy match tag [segment]", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "*kemba*")
+		_ = os.Setenv("KEMBA_MATCH", "segment")
+
+		k := New("test:kemba:fail")
+		is.False(k.enabled, "Logger should NOT be enabled")
+
+		_ = os.Setenv("DEBUG", "**kemba**")
+		k = New("test:kemba:fail")
+		is.True(k.enabled, "Logger should be enabled")
+
+		_ = os.Setenv("KEMBA_MATCH", "")
+	})
+
 	t.Run("fuzzy match tag [failure]", func(t *testing.T) {
 		_ = os.Setenv("DEBUG", "*kemba")
 
@@ -84,156 +107,28 @@ func Test_New(t *testing.T) {
 		k := New("test:kemba:fail")
 		is.True(k.enabled, "Logger should be enabled")
 	})
-}
-
-func Example() {
-	_ = os.Setenv("DEBUG", "example:*")
-	// OR
-	// _ = os.Setenv("KEMBA", "example:*")
-	k := New("example:tag")
-
-	type myType struct {
-		a, b int
-	}
-
-	var x = []myType{{1, 2}, {3, 4}}
-	k.Printf("%#v", x)
-	// Output to os.Stderr
-	// example:tag []main.myType{main.myType{a:1, b:2}, main.myType{a:3, b:4}} +0s
-
-	// Artificial delay to demonstrate the time tagging
-	time.Sleep(250 * time.Millisecond)
-	k.Printf("%# v", x)
-	k.Println(x)
-
-	// Artificial delay to demonstrate the time tagging
-	time.Sleep(100 * time.Millisecond)
-	k.Log(x)
-	// All result in the same output to os.Stderr
-	// example:tag []main.myType{ +XXs
-	// example:tag     {a:1, b:2},
-	// example:tag     {a:3, b:4},
-	// example:tag }
-
-	// Create a new extended logger with a new tag
-	k1 := k.Extend("1")
-	k1.Println("a string", 12, true)
-	// Output to os.Stderr
-	// example:tag:1 a string +0s
-	// example:tag:1 int(12)
-	// example:tag:1 bool(true)
-	_ = os.Setenv("DEBUG", "")
-
-	// Output:
-	// example:tag []kemba.myType{kemba.myType{a:1, b:2}, kemba.myType{a:3, b:4}} +0s
-	// example:tag []kemba.myType{ +250ms
-	// example:tag     {a:1, b:2},
-	// example:tag     {a:3, b:4},
-	// example:tag }
-	// example:tag []kemba.myType{ +0s
-	// example:tag     {a:1, b:2},
-	// example:tag     {a:3, b:4},
-	// example:tag }
-	// example:tag []kemba.myType{ +105ms
-	// example:tag     {a:1, b:2},
-	// example:tag     {a:3, b:4},
-	// example:tag }
-	// example:tag:1 a string +0s
-	// example:tag:1 int(12)
-	// example:tag:1 bool(true)
-}
-
-func ExampleKemba_Printf() {
-	_ = os.Setenv("DEBUG", "test:*")
-	k := New("test:kemba")
-	k.Printf("%s", "Hello")
-
-	k1 := k.Extend("1")
-	k1.Printf("%s", "Hello 1")
-
-	k2 := k.Extend("2")
-	k2.Printf("%s", "Hello 2")
 
-	k3 := k.Extend("3")
-	k3.Printf("%s", "Hello 3")
+	t.Run("skip tag", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*,-test:kemba")
 
-	s := []string{"test", "again", "third"}
-	k2.Printf("%# v", s)
-
-	m := map[string]int{
-		"test":  1,
-		"again": 1337,
-		"third": 732,
-	}
-	k1.Printf("%# v", m)
-
-	type myType struct {
-		a int
-		b int
-	}
-	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
-	k3.Printf("%# v", x)
-	k2.Printf("%#v", x)
-
-	k.Printf("%#v %#v %#v %#v %#v %#v", m, s, m, s, m, s)
-	_ = os.Setenv("DEBUG", "")
-
-	// Output:
-	// test:kemba Hello +0s
-	// test:kemba:1 Hello 1 +0s
-	// test:kemba:2 Hello 2 +0s
-	// test:kemba:3 Hello 3 +0s
-	// test:kemba:2 []string{"test", "again", "third"} +0s
-	// test:kemba:1 map[string]int{"again":1337, "third":732, "test":1} +0s
-	// test:kemba:3 []kemba.myType{ +0s
-	// test:kemba:3     {a:1, b:2},
-	// test:kemba:3     {a:3, b:4},
-	// test:kemba:3     {a:5, b:6},
-	// test:kemba:3 }
-	// test:kemba:2 []kemba.myType{kemba.myType{a:1, b:2}, kemba.myType{a:3, b:4}, kemba.myType{a:5, b:6}} +0s
-	// test:kemba map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} map[string]int{"again":1337, "test":1, "third":732} []string{"test", "again", "third"} +0s
-}
-
-func ExampleKemba_Printf_expanded() {
-	_ = os.Setenv("DEBUG", "test:*")
-	k := New("test:kemba")
-	k.Printf("%s", "Hello")
-
-	type myType struct {
-		a int
-		b int
-	}
-	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+		k := New("test:kemba")
+		is.False(k.enabled, "Logger should NOT be enabled")
 
-	// NOTE: The "%# v" operand for the Printf format.
-	k.Printf("%# v", x)
-	_ = os.Setenv("DEBUG", "")
+		k = New("test:other")
+		is.True(k.enabled, "Logger should be enabled")
+	})
 
-	// Output:
-	// test:kemba Hello +0s
-	// test:kemba []kemba.myType{ +0s
-	// test:kemba     {a:1, b:2},
-	// test:kemba     {a:3, b:4},
-	// test:kemba     {a:5, b:6},
-	// test:kemba }
-}
+	t.Run("skip tag from KEMBA", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*")
+		_ = os.Setenv("KEMBA", "-test:kemba*")
 
-func ExampleKemba_Printf_compact() {
-	_ = os.Setenv("DEBUG", "test:*")
-	k := New("test:kemba")
+		k := New("test:kemba:poller")
+		is.False(k.enabled, "Logger should NOT be enabled")
 
-	type myType struct {
-		a int
-		b int
-	}
-	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
+		_ = os.Setenv("KEMBA", "")
+	})
 
-	// NOTE: The "%#v" operand for the Printf format.
-	k.Printf("%#v", x)
 	_ = os.Setenv("DEBUG", "")
-
-	// Output:
-	// test:kemba []kemba.myType{kemba.myType{a:1, b:2}, kemba.myType{a:3, b:4}, kemba.myType{a:5, b:6}} +0s
 }
 
 func Test_Printf(t *testing.T) {
@@ -373,6 +268,7 @@ string`
 
 	t.Run("should prepend tag on simple string w/ color", func(t *testing.T) {
 		_ = os.Setenv("DEBUG", "test:*")
+		_ = os.Setenv("FORCE_COLOR", "1")
 
 		rescueStderr := os.Stderr
 		r, w, _ := os.Pipe()
@@ -392,32 +288,10 @@ string`
 		}
 
 		_ = os.Setenv("DEBUG", "")
+		_ = os.Unsetenv("FORCE_COLOR")
 	})
 }
 
-func ExampleKemba_Println() {
-	_ = os.Setenv("DEBUG", "test:*")
-	k := New("test:kemba")
-	k.Printf("%s", "Hello")
-
-	type myType struct {
-		a int
-		b int
-	}
-	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
-	k.Println(x)
-
-	_ = os.Setenv("DEBUG", "")
-
-	// Output:
-	// test:kemba Hello +0s
-	// test:kemba []kemba.myType{ +0s
-	// test:kemba     {a:1, b:2},
-	// test:kemba     {a:3, b:4},
-	// test:kemba     {a:5, b:6},
-	// test:kemba }
-}
-
 func Test_Println(t *testing.T) {
 	is := assert.New(t)
 
@@ -559,6 +433,7 @@ string`
 
 	t.Run("should prepend tag on simple string w/ color", func(t *testing.T) {
 		_ = os.Setenv("DEBUG", "test:*")
+		_ = os.Setenv("FORCE_COLOR", "1")
 
 		rescueStderr := os.Stderr
 		r, w, _ := os.Pipe()
@@ -578,32 +453,11 @@ string`
 		}
 
 		_ = os.Setenv("DEBUG", "")
+		_ = os.Unsetenv("FORCE_COLOR")
 	})
 
 }
 
-func ExampleKemba_Log() {
-	_ = os.Setenv("DEBUG", "test:*")
-	k := New("test:kemba")
-	k.Printf("%s", "Hello")
-
-	type myType struct {
-		a, b int
-	}
-	var x = []myType{{1, 2}, {3, 4}, {5, 6}}
-	k.Log(x)
-
-	_ = os.Setenv("DEBUG", "")
-
-	// Output:
-	// test:kemba Hello +0s
-	// test:kemba []kemba.myType{ +0s
-	// test:kemba     {a:1, b:2},
-	// test:kemba     {a:3, b:4},
-	// test:kemba     {a:5, b:6},
-	// test:kemba }
-}
-
 func Test_Log(t *testing.T) {
 	is := assert.New(t)
 
@@ -667,6 +521,38 @@ func Test_Extend(t *testing.T) {
 	})
 }
 
+func Test_Kemba(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should be a disabled logger when not created with New", func(t *testing.T) {
+		StartFlightRecorder(10)
+		defer StartFlightRecorder(0)
+
+		var k Kemba
+		is.NotPanics(func() {
+			k.Printf("test")
+			k.Println("test")
+			k.Log("test")
+			k.Logw("test", "id", 1)
+			k.With("id", 1).Printf("test")
+			k.Time("span")()
+
+			w := k.Writer()
+			_, _ = w.Write([]byte("test\n"))
+			_ = w.Close()
+			k.StdLogger().Print("test")
+			slog.New(k.Handler()).Info("test")
+		})
+
+		is.False(k.isEnabled())
+		is.Empty(k.Timings())
+
+		var buf bytes.Buffer
+		is.NoError(Dump(&buf))
+		is.Equal("", buf.String())
+	})
+}
+
 func Test_PickColor(t *testing.T) {
 	is := assert.New(t)
 
@@ -676,6 +562,31 @@ func Test_PickColor(t *testing.T) {
 		c := color.Color256{81, 0}
 		is.Equal(c.Value(), out.Value())
 	})
+
+	t.Run("should return the colors picked by previous versions", func(t *testing.T) {
+		expected := map[string]uint8{
+			"test:kemba":  81,
+			"example:tag": 74,
+			"app":         62,
+			"app:db":      42,
+			"app:http":    163,
+			"kemba":       42,
+		}
+
+		for tag, v := range expected {
+			c := color.Color256{v, 0}
+			is.Equal(c.Value(), PickColor(tag).Value(), tag)
+		}
+	})
+
+	t.Run("should not reseed the global source", func(t *testing.T) {
+		rand.Seed(1337)
+		expected := rand.Int63()
+
+		rand.Seed(1337)
+		_ = PickColor("test:kemba:not-cached")
+		is.Equal(expected, rand.Int63())
+	})
 }
 
 func Test_Private_getDebugFlagFromEnv(t *testing.T) {
@@ -719,3 +630,27 @@ func Test_Private_getDebugFlagFromEnv(t *testing.T) {
 		_ = os.Setenv("KEMBA", "")
 	})
 }
+
+func Test_Private_determineEnabled(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should enable on an include term", func(t *testing.T) {
+		is.True(determineEnabled("app:db", "app:*"))
+		is.True(determineEnabled("app:db", "other,app:db"))
+	})
+
+	t.Run("should disable on a skip term", func(t *testing.T) {
+		is.False(determineEnabled("app:poller", "app:*,-app:poller*"))
+		is.False(determineEnabled("app:poller:tick", "app:*,-app:poller*"))
+		is.True(determineEnabled("app:db", "app:*,-app:poller*"))
+	})
+
+	t.Run("should let a skip win regardless of order", func(t *testing.T) {
+		is.False(determineEnabled("app:poller", "-app:poller,app:*"))
+		is.False(determineEnabled("app:poller", "app:poller,-app:poller"))
+	})
+
+	t.Run("should not enable on a skip term alone", func(t *testing.T) {
+		is.False(determineEnabled("app:db", "-app:poller"))
+	})
+}
@@ -0,0 +1,58 @@
+package kembatest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clok/kemba"
+)
+
+// Clock is a kemba.Clock whose time only changes when it is advanced, so that the timestamps
+// and time deltas of log events are deterministic. It is safe for concurrent use.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ kemba.Clock = (*Clock)(nil)
+
+// NewClock returns a fake clock set to the provided time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance will move the clock forward by the duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set will move the clock to the provided time.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// UseClock will make every logger that was not created with WithClock read the time from the
+// clock until the test completes.
+//
+// Loggers are global, so UseClock must not be used by tests running in parallel.
+func UseClock(t testing.TB, c kemba.Clock) {
+	t.Helper()
+
+	prev := kemba.SetClock(c)
+	t.Cleanup(func() { kemba.SetClock(prev) })
+}
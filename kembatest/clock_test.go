@@ -0,0 +1,45 @@
+package kembatest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clok/kemba"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Clock(t *testing.T) {
+	is := assert.New(t)
+
+	start := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should only change when advanced", func(t *testing.T) {
+		c := NewClock(start)
+		is.Equal(start, c.Now())
+
+		c.Advance(1500 * time.Millisecond)
+		is.Equal(start.Add(1500*time.Millisecond), c.Now())
+
+		c.Set(start)
+		is.Equal(start, c.Now())
+	})
+
+	t.Run("should make the deltas of captured loggers deterministic", func(t *testing.T) {
+		k := kemba.New("test:clock")
+
+		c := NewClock(start)
+		UseClock(t, c)
+		rec := Capture(t, "test:*")
+
+		c.Advance(250 * time.Millisecond)
+		k.Printf("first")
+		c.Advance(20 * time.Millisecond)
+		k.Printf("second")
+
+		records := rec.Records()
+		is.Len(records, 2)
+		is.Equal(250*time.Millisecond, records[0].Delta)
+		is.Equal(start.Add(250*time.Millisecond), records[0].Time)
+		is.Equal(20*time.Millisecond, records[1].Delta)
+	})
+}
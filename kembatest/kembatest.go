@@ -0,0 +1,119 @@
+// Package kembatest provides helpers to capture the output of kemba loggers in tests.
+//
+// Example:
+//
+//	func TestSync(t *testing.T) {
+//		rec := kembatest.Capture(t, "app:sync:*")
+//
+//		sync.Run()
+//
+//		for _, r := range rec.Records() {
+//			t.Log(r.Tag, r.Lines, r.Delta)
+//		}
+//	}
+package kembatest
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clok/kemba"
+)
+
+// Record is a log event captured by a Recorder.
+type Record struct {
+	Time   time.Time
+	Tag    string
+	Lines  []string
+	Delta  time.Duration
+	Caller string
+	Fields map[string]interface{}
+}
+
+// Recorder holds the log events captured during a test. It is safe for concurrent use.
+type Recorder struct {
+	t       testing.TB
+	mu      sync.Mutex
+	records []Record
+}
+
+// jsonRecord is the JSON representation of a log event written by kemba.
+type jsonRecord struct {
+	Time    time.Time              `json:"time"`
+	NS      string                 `json:"ns"`
+	Msg     string                 `json:"msg"`
+	DeltaMS int64                  `json:"delta_ms"`
+	Caller  string                 `json:"caller"`
+	Fields  map[string]interface{} `json:"fields"`
+}
+
+// Capture will enable the patterns for the duration of the test and record the output of every
+// logger that was not created with WithWriter, including loggers created before the call.
+// The previous patterns and output are restored when the test completes.
+//
+// Loggers are global, so Capture must not be used by tests running in parallel.
+func Capture(t testing.TB, patterns string) *Recorder {
+	t.Helper()
+
+	r := &Recorder{t: t}
+	t.Cleanup(kemba.Redirect(patterns, recorderWriter{r}, kemba.FormatJSON))
+
+	return r
+}
+
+// Records returns the log events captured so far.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Record(nil), r.records...)
+}
+
+// Lines returns the lines of the log events captured so far, for the tag when it is not empty.
+func (r *Recorder) Lines(tag string) []string {
+	var lines []string
+	for _, rec := range r.Records() {
+		if tag == "" || rec.Tag == tag {
+			lines = append(lines, rec.Lines...)
+		}
+	}
+	return lines
+}
+
+// Reset will drop the log events captured so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+}
+
+// recorderWriter parses the log events written by kemba, one per write.
+type recorderWriter struct {
+	r *Recorder
+}
+
+// Write will parse and record the log event, failing the test when it is malformed.
+func (w recorderWriter) Write(p []byte) (int, error) {
+	var jr jsonRecord
+	if err := json.Unmarshal(p, &jr); err != nil {
+		w.r.t.Errorf("kembatest: malformed log event %q: %v", p, err)
+		return len(p), nil
+	}
+
+	w.r.mu.Lock()
+	defer w.r.mu.Unlock()
+
+	w.r.records = append(w.r.records, Record{
+		Time:   jr.Time,
+		Tag:    jr.NS,
+		Lines:  strings.Split(jr.Msg, "\n"),
+		Delta:  time.Duration(jr.DeltaMS) * time.Millisecond,
+		Caller: jr.Caller,
+		Fields: jr.Fields,
+	})
+	return len(p), nil
+}
@@ -0,0 +1,62 @@
+package kembatest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/clok/kemba"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Capture(t *testing.T) {
+	is := assert.New(t)
+
+	k := kemba.New("test:kembatest")
+
+	t.Run("should record the output of existing and new loggers", func(t *testing.T) {
+		rec := Capture(t, "test:**")
+
+		k.Printf("first\nsecond")
+		k.Extend("child").With("id", 1).Log("test")
+
+		records := rec.Records()
+		is.Len(records, 2)
+		is.Equal("test:kembatest", records[0].Tag)
+		is.Equal([]string{"first", "second"}, records[0].Lines)
+		is.Equal("test:kembatest:child", records[1].Tag)
+		is.Equal([]string{"test"}, records[1].Lines)
+		is.Equal(map[string]interface{}{"id": float64(1)}, records[1].Fields)
+		is.Equal([]string{"first", "second"}, rec.Lines("test:kembatest"))
+	})
+
+	t.Run("should only record enabled loggers", func(t *testing.T) {
+		rec := Capture(t, "test:other")
+
+		k.Printf("test")
+
+		is.Empty(rec.Records())
+	})
+
+	t.Run("should not record loggers with their own writer", func(t *testing.T) {
+		rec := Capture(t, "test:*")
+
+		var buf bytes.Buffer
+		kemba.NewWithOptions("test:kembatest", kemba.WithWriter(&buf), kemba.WithColor(false)).Printf("test")
+
+		is.Empty(rec.Records())
+		is.Contains(buf.String(), "test:kembatest test")
+	})
+
+	t.Run("should drop the records on reset", func(t *testing.T) {
+		rec := Capture(t, "test:*")
+
+		k.Printf("test")
+		rec.Reset()
+
+		is.Empty(rec.Records())
+	})
+
+	t.Run("should restore the patterns on cleanup", func(t *testing.T) {
+		is.False(kemba.Enabled("test:kembatest"))
+	})
+}
@@ -0,0 +1,158 @@
+package kemba
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchMode is the wildcard semantics used to match namespace patterns against tags.
+type MatchMode int
+
+const (
+	// MatchLegacy matches * as any sequence of characters, including the : delimiter.
+	MatchLegacy MatchMode = iota
+	// MatchSegment matches * within a single : delimited segment, ** across any number of
+	// segments and ? as a single character within a segment. A bare * still matches every tag.
+	MatchSegment
+)
+
+// getMatchModeFromEnv will read the wildcard semantics from the KEMBA_MATCH env value, or the configured Env.Match.
+// A value of "segment" selects MatchSegment, any other value MatchLegacy.
+func getMatchModeFromEnv() MatchMode {
+	if strings.EqualFold(getenv(currentEnv().Match), "segment") {
+		return MatchSegment
+	}
+	return MatchLegacy
+}
+
+// segmentRegexp will convert a term into an anchored regular expression, quoting the literal parts.
+//
+// Example:
+//
+//	app:*      matches app:db, but not app:db:pool
+//	app:**     matches app:db and app:db:pool
+//	app:db:?   matches app:db:1, but not app:db:10
+//	*          matches every tag
+func segmentRegexp(term string) string {
+	if term == "*" {
+		return "^.*$"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(term); {
+		switch {
+		case strings.HasPrefix(term[i:], "**"):
+			sb.WriteString(".*")
+			// Collapse runs of wildcards, as *** matches the same tags as **
+			for i < len(term) && term[i] == '*' {
+				i++
+			}
+		case term[i] == '*':
+			sb.WriteString("[^:]*")
+			i++
+		case term[i] == '?':
+			sb.WriteString("[^:]")
+			i++
+		default:
+			j := strings.IndexAny(term[i:], "*?")
+			if j < 0 {
+				j = len(term) - i
+			}
+			sb.WriteString(regexp.QuoteMeta(term[i : i+j]))
+			i += j
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// PatternError describes an invalid term of the namespace patterns.
+type PatternError struct {
+	// Source is where the patterns were read from, ex. DEBUG, KEMBA or Enable
+	Source string
+	// Term is the invalid term
+	Term string
+	// Reason describes why the term is invalid
+	Reason string
+}
+
+// Error returns the description of the invalid term.
+func (e *PatternError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("kemba: invalid pattern %q: %s", e.Term, e.Reason)
+	}
+	return fmt.Sprintf("kemba: invalid pattern %q in %s: %s", e.Term, e.Source, e.Reason)
+}
+
+// ValidatePatterns will check every term of the namespace patterns and return a PatternError
+// for each term that can not match as intended. An empty string is valid and disables every logger.
+func ValidatePatterns(patterns string) []error {
+	return validatePatterns("", patterns, getMatchModeFromEnv())
+}
+
+// Diagnostics will validate the active namespace patterns, set with Enable or read from the
+// DEBUG and KEMBA environment variables, and return a PatternError for each invalid term.
+func Diagnostics() []error {
+	registry.mu.Lock()
+	sources := registry.sources(nil)
+	registry.mu.Unlock()
+
+	mode := getMatchModeFromEnv()
+
+	var errs []error
+	for _, src := range sources {
+		errs = append(errs, validatePatterns(src.name, src.patterns, mode)...)
+	}
+	return errs
+}
+
+// validatePatterns will check every term of the namespace patterns read from the source.
+func validatePatterns(source string, patterns string, mode MatchMode) []error {
+	if patterns == "" {
+		return nil
+	}
+
+	var errs []error
+	for _, term := range strings.Split(patterns, ",") {
+		if reason := validateTerm(term, mode); reason != "" {
+			errs = append(errs, &PatternError{Source: source, Term: term, Reason: reason})
+		}
+	}
+	return errs
+}
+
+// validateTerm will return the reason the term is invalid, or an empty string when it is valid.
+func validateTerm(term string, mode MatchMode) string {
+	pattern := strings.TrimPrefix(term, "-")
+
+	switch {
+	case term == "":
+		return "empty term"
+	case pattern == "":
+		return "empty skip term"
+	case strings.TrimSpace(term) != term:
+		return "leading or trailing whitespace"
+	case strings.ContainsAny(pattern, " \t\n"):
+		return "whitespace within the term"
+	case strings.HasPrefix(pattern, "-"):
+		return "multiple skip prefixes"
+	case mode == MatchSegment && strings.Contains(pattern, "***"):
+		return "more than two consecutive wildcards"
+	}
+
+	var reg string
+	if mode == MatchLegacy {
+		reg = legacyRegexp(pattern)
+	} else {
+		reg = segmentRegexp(pattern)
+	}
+	if _, err := regexp.Compile(reg); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
@@ -0,0 +1,170 @@
+package kemba
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Private_compileTerm_segment(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should match a single segment with *", func(t *testing.T) {
+		is.True(compileTerm("app:*", MatchSegment).match("app:db"))
+		is.False(compileTerm("app:*", MatchSegment).match("app:db:pool"))
+		is.True(compileTerm("app:*:pool", MatchSegment).match("app:db:pool"))
+		is.False(compileTerm("app:*:pool", MatchSegment).match("app:db:x:pool"))
+		is.True(compileTerm("app:d*", MatchSegment).match("app:db"))
+		is.True(compileTerm("app:*", MatchSegment).match("app:"))
+		is.True(compileTerm("*", MatchSegment).match("app:db:pool"))
+	})
+
+	t.Run("should match any depth with **", func(t *testing.T) {
+		is.True(compileTerm("app:**", MatchSegment).match("app:db"))
+		is.True(compileTerm("app:**", MatchSegment).match("app:db:pool:conn"))
+		is.True(compileTerm("app:**:pool", MatchSegment).match("app:db:x:pool"))
+		is.False(compileTerm("app:**", MatchSegment).match("other:db"))
+		is.True(compileTerm("***", MatchSegment).match("app:db:pool"))
+	})
+
+	t.Run("should match a single character with ?", func(t *testing.T) {
+		is.True(compileTerm("app:db:?", MatchSegment).match("app:db:1"))
+		is.False(compileTerm("app:db:?", MatchSegment).match("app:db:10"))
+		is.False(compileTerm("app?db", MatchSegment).match("app:db"))
+	})
+
+	t.Run("should match literals exactly", func(t *testing.T) {
+		is.True(compileTerm("app:db", MatchSegment).match("app:db"))
+		is.False(compileTerm("app", MatchSegment).match("app:db"))
+		is.True(compileTerm("api.v2:*", MatchSegment).match("api.v2:db"))
+		is.False(compileTerm("api.v2:*", MatchSegment).match("apixv2:db"))
+	})
+}
+
+func Test_Private_getMatchModeFromEnv(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should default to legacy semantics", func(t *testing.T) {
+		is.Equal(MatchLegacy, getMatchModeFromEnv())
+		is.True(determineEnabled("app:db:pool", "app:*"))
+		is.True(determineEnabled("app:db:pool", "*"))
+	})
+
+	t.Run("should read segment semantics from KEMBA_MATCH", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_MATCH", "segment")
+		is.Equal(MatchSegment, getMatchModeFromEnv())
+		is.False(determineEnabled("app:db:pool", "app:*"))
+		is.True(determineEnabled("app:db:pool", "*"))
+
+		_ = os.Setenv("KEMBA_MATCH", "")
+	})
+}
+
+// FuzzMatchSemantics compares the segment aware and legacy wildcard semantics. For terms built from
+// letters, : and *, replacing every * with ** must match the same tags as the legacy semantics,
+// and a segment match must always be a legacy match.
+func FuzzMatchSemantics(f *testing.F) {
+	f.Add("app:db:pool", "app:*")
+	f.Add("app:db:pool", "app:*:pool")
+	f.Add("test:kemba:fail", "*kemba*")
+	f.Add("test:kemba", "test:kemba")
+	f.Add("a:b", "*:*")
+
+	f.Fuzz(func(t *testing.T, tag string, term string) {
+		if strings.Trim(tag, "abcdefghijklmnopqrstuvwxyz:") != "" ||
+			strings.Trim(term, "abcdefghijklmnopqrstuvwxyz:*") != "" {
+			t.Skip()
+		}
+
+		legacy := compileTerm(term, MatchLegacy).match(tag)
+		if compileTerm(term, MatchSegment).match(tag) && !legacy {
+			t.Errorf("segment match of %q with %q is not a legacy match", tag, term)
+		}
+
+		deep := strings.ReplaceAll(term, "*", "**")
+		if compileTerm(deep, MatchSegment).match(tag) != legacy {
+			t.Errorf("segment match of %q with %q differs from legacy match with %q", tag, deep, term)
+		}
+	})
+}
+
+func Test_Private_compileTerm_legacy(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should quote regular expression metacharacters", func(t *testing.T) {
+		is.True(compileTerm("api.v2:*", MatchLegacy).match("api.v2:db"))
+		is.False(compileTerm("api.v2:*", MatchLegacy).match("apixv2:db"))
+		is.True(compileTerm("app+:*", MatchLegacy).match("app+:db"))
+		is.False(compileTerm("app+:*", MatchLegacy).match("appp:db"))
+		is.True(compileTerm("app(1:*", MatchLegacy).match("app(1:db"))
+		is.True(compileTerm("app[x]:*", MatchLegacy).match("app[x]:db"))
+		is.False(compileTerm("app[x]:*", MatchLegacy).match("appx:db"))
+	})
+
+	t.Run("should accept anchors", func(t *testing.T) {
+		is.True(compileTerm("^app:*$", MatchLegacy).match("app:db"))
+		is.True(compileTerm("^app:*", MatchLegacy).match("app:db:pool"))
+		is.False(compileTerm("^app:*", MatchLegacy).match("other:app:db"))
+	})
+}
+
+func Test_ValidatePatterns(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should accept valid patterns", func(t *testing.T) {
+		is.Empty(ValidatePatterns(""))
+		is.Empty(ValidatePatterns("app:**,-app:poller,api.v2:*,app:db:?"))
+	})
+
+	t.Run("should report invalid terms", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_MATCH", "segment")
+		defer func() { _ = os.Setenv("KEMBA_MATCH", "") }()
+
+		errs := ValidatePatterns("app:*,, other,-,--app,app:***,a b")
+
+		is.Len(errs, 6)
+		is.EqualError(errs[0], `kemba: invalid pattern "": empty term`)
+		is.EqualError(errs[1], `kemba: invalid pattern " other": leading or trailing whitespace`)
+		is.EqualError(errs[2], `kemba: invalid pattern "-": empty skip term`)
+		is.EqualError(errs[3], `kemba: invalid pattern "--app": multiple skip prefixes`)
+		is.EqualError(errs[4], `kemba: invalid pattern "app:***": more than two consecutive wildcards`)
+		is.EqualError(errs[5], `kemba: invalid pattern "a b": whitespace within the term`)
+	})
+}
+
+func Test_Diagnostics(t *testing.T) {
+	is := assert.New(t)
+	defer registry.set(nil)
+
+	t.Run("should report invalid terms of the environment", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "app:*,")
+		_ = os.Setenv("KEMBA", "-")
+
+		errs := Diagnostics()
+
+		is.Len(errs, 2)
+		is.EqualError(errs[0], `kemba: invalid pattern "" in DEBUG: empty term`)
+		is.EqualError(errs[1], `kemba: invalid pattern "-" in KEMBA: empty skip term`)
+
+		var pe *PatternError
+		is.ErrorAs(errs[1], &pe)
+		is.Equal("KEMBA", pe.Source)
+
+		_ = os.Setenv("DEBUG", "")
+		_ = os.Setenv("KEMBA", "")
+	})
+
+	t.Run("should report invalid terms set with Enable", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_MATCH", "segment")
+		defer func() { _ = os.Setenv("KEMBA_MATCH", "") }()
+
+		Enable("app:***")
+
+		errs := Diagnostics()
+
+		is.Len(errs, 1)
+		is.EqualError(errs[0], `kemba: invalid pattern "app:***" in Enable: more than two consecutive wildcards`)
+	})
+}
@@ -0,0 +1,137 @@
+package kemba
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxCachedMatchers bounds the number of compiled matchers kept in the cache.
+const maxCachedMatchers = 64
+
+// Matcher is a compiled set of namespace patterns.
+//
+// A Matcher is immutable and safe for concurrent use.
+type Matcher struct {
+	includes []matcherTerm
+	skips    []matcherTerm
+}
+
+// matcherTerm is a single compiled term. Terms without wildcards are matched exactly.
+type matcherTerm struct {
+	literal string
+	re      *regexp.Regexp
+}
+
+// matcherKey identifies a compiled matcher in the cache.
+type matcherKey struct {
+	patterns string
+	mode     MatchMode
+}
+
+// matcherCache holds the compiled matchers by patterns and wildcard semantics.
+type matcherCache struct {
+	mu       sync.RWMutex
+	matchers map[matcherKey]*Matcher
+}
+
+var matchers = &matcherCache{}
+
+// NewMatcher returns the compiled namespace patterns, using the wildcard semantics selected by KEMBA_MATCH.
+// Matchers are cached, so compiling the same patterns again is cheap.
+func NewMatcher(patterns string) *Matcher {
+	return compileMatcher(patterns, getMatchModeFromEnv())
+}
+
+// Match reports if the tag matches any include term and no skip term.
+func (m *Matcher) Match(tag string) bool {
+	for _, t := range m.skips {
+		if t.match(tag) {
+			return false
+		}
+	}
+
+	for _, t := range m.includes {
+		if t.match(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match will test the term against the tag.
+func (t matcherTerm) match(tag string) bool {
+	if t.re == nil {
+		return t.literal == tag
+	}
+	return t.re.MatchString(tag)
+}
+
+// compileMatcher returns the cached matcher for the patterns, compiling it when missing.
+func compileMatcher(patterns string, mode MatchMode) *Matcher {
+	key := matcherKey{patterns: patterns, mode: mode}
+
+	matchers.mu.RLock()
+	m, ok := matchers.matchers[key]
+	matchers.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	m = newMatcher(patterns, mode)
+
+	matchers.mu.Lock()
+	defer matchers.mu.Unlock()
+
+	if matchers.matchers == nil || len(matchers.matchers) >= maxCachedMatchers {
+		matchers.matchers = make(map[matcherKey]*Matcher)
+	}
+	matchers.matchers[key] = m
+
+	return m
+}
+
+// reset will drop every cached matcher.
+func (c *matcherCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.matchers = nil
+}
+
+// newMatcher will split the patterns by , and compile each term.
+func newMatcher(patterns string, mode MatchMode) *Matcher {
+	m := &Matcher{}
+	if patterns == "" {
+		return m
+	}
+
+	for _, l := range strings.Split(patterns, ",") {
+		if strings.HasPrefix(l, "-") {
+			m.skips = append(m.skips, compileTerm(l[1:], mode))
+		} else {
+			m.includes = append(m.includes, compileTerm(l, mode))
+		}
+	}
+
+	return m
+}
+
+// compileTerm will compile a single term with the provided wildcard semantics.
+// The literal parts are quoted, so the regular expression always compiles.
+func compileTerm(term string, mode MatchMode) matcherTerm {
+	if mode == MatchLegacy && !strings.Contains(term, "*") ||
+		mode == MatchSegment && !strings.ContainsAny(term, "*?") {
+		return matcherTerm{literal: term}
+	}
+
+	var reg string
+	if mode == MatchLegacy {
+		reg = legacyRegexp(term)
+	} else {
+		reg = segmentRegexp(term)
+	}
+
+	return matcherTerm{re: regexp.MustCompile(reg)}
+}
@@ -0,0 +1,106 @@
+package kemba
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Matcher(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should match includes and skips", func(t *testing.T) {
+		m := NewMatcher("app:**,-app:poller,api.v2:db")
+
+		is.True(m.Match("app:db"))
+		is.True(m.Match("app:db:pool"))
+		is.True(m.Match("api.v2:db"))
+		is.False(m.Match("app:poller"))
+		is.False(m.Match("other"))
+	})
+
+	t.Run("should not match anything for empty patterns", func(t *testing.T) {
+		is.False(NewMatcher("").Match(""))
+		is.False(NewMatcher("").Match("app"))
+	})
+
+	t.Run("should return the cached matcher for the same patterns", func(t *testing.T) {
+		is.Same(NewMatcher("app:*,other"), NewMatcher("app:*,other"))
+		is.NotSame(NewMatcher("app:*,other"), compileMatcher("app:*,other", MatchSegment))
+	})
+
+	t.Run("should invalidate the cache when the active patterns change", func(t *testing.T) {
+		defer registry.set(nil)
+
+		m := NewMatcher("app:*")
+		Enable("app:*")
+
+		is.NotSame(m, NewMatcher("app:*"))
+	})
+
+	t.Run("should bound the cache", func(t *testing.T) {
+		for i := 0; i < maxCachedMatchers*2; i++ {
+			_ = NewMatcher(fmt.Sprintf("app:%d", i))
+		}
+
+		matchers.mu.RLock()
+		is.LessOrEqual(len(matchers.matchers), maxCachedMatchers)
+		matchers.mu.RUnlock()
+	})
+}
+
+// uncachedDetermineEnabled is the previous implementation of determineEnabled, which splits the
+// allowed string and compiles a regular expression per wildcard term on every call.
+// It is kept as the baseline of the benchmarks.
+func uncachedDetermineEnabled(tag string, allowed string) bool {
+	var a bool
+	for _, l := range strings.Split(allowed, ",") {
+		if strings.HasPrefix(l, "-") {
+			if uncachedMatchTerm(tag, l[1:]) {
+				return false
+			}
+		} else if !a {
+			a = uncachedMatchTerm(tag, l)
+		}
+	}
+	return a
+}
+
+func uncachedMatchTerm(tag string, term string) bool {
+	if !strings.ContainsAny(term, "*?") {
+		return term == tag
+	}
+
+	m, _ := regexp.MatchString(segmentRegexp(term), tag)
+	return m
+}
+
+var benchmarkPatterns = []struct {
+	name    string
+	allowed string
+}{
+	{"exact", "app:db"},
+	{"wildcard", "app:*"},
+	{"many", "app:**,-app:poller**,api.v2:*,worker:?,http:*:handler,db:**"},
+}
+
+func Benchmark_determineEnabled(b *testing.B) {
+	for _, bp := range benchmarkPatterns {
+		b.Run(bp.name+"/uncached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = uncachedDetermineEnabled("app:db:pool", bp.allowed)
+			}
+		})
+
+		b.Run(bp.name+"/cached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = determineEnabled("app:db:pool", bp.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package kemba
+
+import (
+	"io"
+	"time"
+)
+
+// Option configures a Kemba logger created with NewWithOptions.
+type Option func(*config)
+
+// config holds the resolved settings for a Kemba logger.
+type config struct {
+	writer  io.Writer
+	color   *bool
+	level   *ColorLevel
+	allowed *string
+	clock   Clock
+	format  *Format
+	caller  *Caller
+	time    *TimeMode
+}
+
+// WithWriter sets the destination of the log output. Defaults to os.Stderr.
+//
+// Each log event is a single write, and the writes of a logger never overlap. Loggers sharing a
+// writer that is not safe for concurrent use, such as a bytes.Buffer, must not log concurrently.
+func WithWriter(w io.Writer) Option {
+	return func(c *config) {
+		c.writer = w
+	}
+}
+
+// WithColor forces colored output on or off, with the 256 color palette. When not provided, colors
+// are enabled when the writer is a terminal, honoring the NO_COLOR, NOCOLOR, FORCE_COLOR and TERM
+// environment variables.
+func WithColor(enabled bool) Option {
+	return func(c *config) {
+		c.color = &enabled
+	}
+}
+
+// WithColorLevel forces the color capability of the output, in place of WithColor and detection.
+func WithColorLevel(level ColorLevel) Option {
+	return func(c *config) {
+		c.level = &level
+	}
+}
+
+// WithAllowed sets the namespace patterns used to determine if the logger is enabled,
+// in place of the DEBUG and KEMBA environment variables.
+//
+// Passing an empty string disables the logger regardless of the environment. Loggers created
+// with this option are not affected by Enable and Disable.
+func WithAllowed(allowed string) Option {
+	return func(c *config) {
+		c.allowed = &allowed
+	}
+}
+
+// Clock reads the current time, to stamp log events and compute the time deltas.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock reads the current time with time.Now.
+var systemClock Clock = ClockFunc(time.Now)
+
+// WithClock sets the clock used to stamp log events and compute the time deltas.
+// Defaults to the clock set with SetClock, or time.Now.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithFormat sets the output format. When not provided, the format is read from the
+// KEMBA_FORMAT environment variable, where "json" selects FormatJSON.
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = &f
+	}
+}
+
+// WithCaller sets the caller annotation mode. When not provided, the mode is read from the
+// KEMBA_CALLER environment variable, where "1" selects CallerFile and "func" selects CallerFunc.
+func WithCaller(mode Caller) Option {
+	return func(c *config) {
+		c.caller = &mode
+	}
+}
+
+// WithTime sets the time information added to text output. When not provided, the mode is read
+// from the KEMBA_TIME environment variable, one of delta, iso, unix, both or none, and defaults to TimeAuto.
+//
+// JSON output always includes the timestamp and the time delta.
+func WithTime(mode TimeMode) Option {
+	return func(c *config) {
+		c.time = &mode
+	}
+}
+
+// newConfig applies the provided options. The settings not provided by an option are read from
+// the environment when the logger is updated, along with the writer and the settings depending on it.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
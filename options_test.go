@@ -0,0 +1,81 @@
+package kemba
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewWithOptions(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should write to the provided writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		k.Printf("key: %s value: %d", "test", 1337)
+
+		is.Regexp(`^test:kemba key: test value: 1337 \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should ignore the environment when allowed is provided", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*")
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed(""))
+		k.Printf("key: %s value: %d", "test", 1337)
+
+		is.False(k.enabled, "Logger should NOT be enabled")
+		is.Equal("", buf.String())
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should fall back to the environment when allowed is not provided", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*")
+
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}))
+		is.True(k.enabled, "Logger should be enabled")
+		is.Equal("test:*", k.allowed)
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should force colors on", func(t *testing.T) {
+		_ = os.Setenv("NOCOLOR", "1")
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(true))
+		k.Printf("key: %s value: %d", "test", 1337)
+
+		is.True(k.color)
+		is.Contains(buf.String(), "key: test value: 1337")
+
+		_ = os.Setenv("NOCOLOR", "")
+	})
+
+	t.Run("should use the provided clock for deltas", func(t *testing.T) {
+		now := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false), WithClock(ClockFunc(clock)))
+		now = now.Add(1500 * time.Millisecond)
+		k.Printf("first")
+		now = now.Add(20 * time.Millisecond)
+		k.Printf("second")
+
+		is.Equal("test:kemba first +1.5s\ntest:kemba second +20ms\n", buf.String())
+	})
+
+	t.Run("should carry options to extended loggers", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		ke := k.Extend("extended-walrus")
+		ke.Printf("key: %s value: %d", "test", 1337)
+
+		is.Regexp(`^test:kemba:extended-walrus key: test value: 1337 \+\d+\S+\n$`, buf.String())
+	})
+}
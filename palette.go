@@ -0,0 +1,221 @@
+package kemba
+
+import (
+	"bytes"
+	"hash/crc64"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gookit/color"
+)
+
+// Palette is the set of 256 colors the color of each tag is picked from.
+type Palette []uint8
+
+var (
+	// PaletteDefault is the palette of previous versions, readable on most backgrounds.
+	PaletteDefault = Palette{
+		20, 21, 26, 27, 32, 33, 38, 39, 40, 41, 42, 43, 44, 45, 56, 57, 62, 63, 68, 69,
+		74, 75, 76, 77, 78, 79, 80, 81, 92, 93, 98, 99, 112, 113, 128, 129, 134, 135, 148, 149,
+		160, 161, 162, 163, 164, 165, 166, 167, 168, 169, 170, 171, 172, 173, 178, 179, 184, 185, 196, 197,
+		198, 199, 200, 201, 202, 203, 204, 205, 206, 207, 208, 209, 214, 215, 220,
+	}
+
+	// PaletteDark holds bright colors, for terminals with a dark background.
+	PaletteDark = Palette{
+		39, 45, 49, 50, 51, 75, 81, 87, 111, 117, 118, 119, 120, 121, 123, 141, 147, 153, 154, 155,
+		156, 159, 177, 183, 190, 191, 192, 198, 199, 203, 204, 205, 207, 208, 209, 212, 213, 214, 215, 216,
+		219, 220, 221, 222, 226, 227, 228, 229,
+	}
+
+	// PaletteLight holds dark colors, for terminals with a light background.
+	PaletteLight = Palette{
+		17, 18, 19, 20, 22, 23, 24, 25, 26, 28, 29, 30, 31, 52, 53, 54, 55, 56, 57, 58,
+		64, 88, 89, 90, 91, 92, 94, 100, 124, 125, 126, 127, 128, 130, 136, 160, 161, 162, 164, 166,
+		172, 202,
+	}
+
+	// PaletteColorblind holds the Okabe-Ito colors, which remain distinguishable with
+	// the common forms of color blindness.
+	PaletteColorblind = Palette{178, 74, 36, 185, 25, 166, 175}
+)
+
+// palettes are the palettes selectable with KEMBA_PALETTE.
+var palettes = map[string]Palette{
+	"default":    PaletteDefault,
+	"dark":       PaletteDark,
+	"light":      PaletteLight,
+	"colorblind": PaletteColorblind,
+}
+
+// colorOverride is a color assigned to the tags matching the patterns.
+type colorOverride struct {
+	patterns string
+	color    uint8
+}
+
+var (
+	colorMu sync.RWMutex
+	// palette is the palette set with SetPalette, nil when it is read from the environment.
+	palette Palette
+	// overrides are the colors assigned with SetColor.
+	overrides []colorOverride
+
+	table = crc64.MakeTable(crc64.ISO)
+)
+
+// SetPalette will select the palette the colors of tags are picked from, and re-color every logger.
+// A nil palette restores the palette selected by KEMBA_PALETTE.
+//
+// Example:
+//
+//	kemba.SetPalette(kemba.PaletteColorblind)
+func SetPalette(p Palette) {
+	colorMu.Lock()
+	palette = append(Palette(nil), p...)
+	if len(palette) == 0 {
+		palette = nil
+	}
+	colorMu.Unlock()
+
+	resetPickedColors()
+	registry.refresh()
+}
+
+// SetColor will assign the 256 color to the tags matching the patterns, and re-color every logger.
+// The patterns use the same wildcard semantics as DEBUG. When several assignments match a tag,
+// the last one wins, and assignments made with SetColor win over KEMBA_COLORS.
+//
+// Example:
+//
+//	kemba.SetColor("app:db:**", 196)
+func SetColor(patterns string, c uint8) {
+	colorMu.Lock()
+	overrides = append(overrides, colorOverride{patterns: patterns, color: c})
+	colorMu.Unlock()
+
+	resetPickedColors()
+	registry.refresh()
+}
+
+// ResetColors will drop the colors assigned with SetColor and the palette set with SetPalette.
+func ResetColors() {
+	colorMu.Lock()
+	palette = nil
+	overrides = nil
+	colorMu.Unlock()
+
+	resetPickedColors()
+	registry.refresh()
+}
+
+// PickColor will return the same color based on input string.
+//
+// We want to pick the same color for a given tag to ensure consistent output behavior.
+// A color assigned with SetColor or KEMBA_COLORS is used when one matches the tag. Otherwise
+// the checksum of the tag indexes the palette. The picked color is cached for the tag.
+func PickColor(tag string) *color.Color256 {
+	if c, ok := pickedColors.Load(tag); ok {
+		s := c.(color.Color256)
+		return &s
+	}
+
+	s := color.C256(pickColor(tag))
+	pickedColors.Store(tag, s)
+	return &s
+}
+
+// pickColor will return the assigned color of the tag, or pick one from the palette.
+func pickColor(tag string) uint8 {
+	if c, ok := assignedColor(tag); ok {
+		return c
+	}
+
+	p := currentPalette()
+	sum := crc64.Checksum([]byte(tag), table)
+	if bytes.Equal(p, PaletteDefault) {
+		return p[legacyIndex(sum, len(p))]
+	}
+
+	return p[sum%uint64(len(p))]
+}
+
+// legacySources are math/rand sources reused to pick the colors of previous versions.
+var legacySources = sync.Pool{
+	New: func() interface{} {
+		return rand.New(rand.NewSource(0))
+	},
+}
+
+// legacyIndex returns the index previous versions picked from the default palette, by seeding
+// math/rand with the checksum of the tag, so that tags keep their colors across versions.
+// Sources are reused, as they are expensive to allocate.
+func legacyIndex(sum uint64, n int) int {
+	r := legacySources.Get().(*rand.Rand)
+	defer legacySources.Put(r)
+
+	r.Seed(int64(sum))
+	return r.Intn(n)
+}
+
+// assignedColor returns the color of the last assignment matching the tag.
+func assignedColor(tag string) (uint8, bool) {
+	colorMu.RLock()
+	assigned := append(getColorsFromEnv(), overrides...)
+	colorMu.RUnlock()
+
+	for i := len(assigned) - 1; i >= 0; i-- {
+		if NewMatcher(assigned[i].patterns).Match(tag) {
+			return assigned[i].color, true
+		}
+	}
+	return 0, false
+}
+
+// currentPalette returns the palette set with SetPalette, or the palette selected by KEMBA_PALETTE.
+func currentPalette() Palette {
+	colorMu.RLock()
+	defer colorMu.RUnlock()
+
+	if palette != nil {
+		return palette
+	}
+	return getPaletteFromEnv()
+}
+
+// resetPickedColors will drop the cached color of every tag.
+func resetPickedColors() {
+	pickedColors.Range(func(key, _ interface{}) bool {
+		pickedColors.Delete(key)
+		return true
+	})
+}
+
+// getPaletteFromEnv returns the palette named by KEMBA_PALETTE, falling back to the default palette.
+func getPaletteFromEnv() Palette {
+	if p, ok := palettes[strings.ToLower(getenv(currentEnv().Palette))]; ok {
+		return p
+	}
+	return PaletteDefault
+}
+
+// getColorsFromEnv will parse the color assignments of KEMBA_COLORS, such as "db=196,http=45".
+// Malformed assignments are ignored.
+func getColorsFromEnv() []colorOverride {
+	var assigned []colorOverride
+	for _, s := range strings.Split(getenv(currentEnv().Colors), ",") {
+		i := strings.LastIndex(s, "=")
+		if i <= 0 {
+			continue
+		}
+
+		c, err := strconv.ParseUint(strings.TrimSpace(s[i+1:]), 10, 8)
+		if err != nil {
+			continue
+		}
+		assigned = append(assigned, colorOverride{patterns: strings.TrimSpace(s[:i]), color: uint8(c)})
+	}
+	return assigned
+}
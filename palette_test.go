@@ -0,0 +1,154 @@
+package kemba
+
+import (
+	"bytes"
+	"hash/crc64"
+	"os"
+	"testing"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetColor(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should assign the color to matching tags", func(t *testing.T) {
+		defer ResetColors()
+
+		SetColor("test:palette:**", 196)
+
+		is.Equal(color.C256(196).Value(), PickColor("test:palette:db").Value())
+		is.Equal(color.C256(196).Value(), PickColor("test:palette:db:query").Value())
+		is.NotEqual(color.C256(196).Value(), PickColor("test:kemba").Value())
+	})
+
+	t.Run("should let the last assignment win", func(t *testing.T) {
+		defer ResetColors()
+
+		SetColor("test:palette:*", 196)
+		SetColor("test:palette:http", 45)
+
+		is.Equal(color.C256(196).Value(), PickColor("test:palette:db").Value())
+		is.Equal(color.C256(45).Value(), PickColor("test:palette:http").Value())
+	})
+
+	t.Run("should re-color existing loggers", func(t *testing.T) {
+		defer ResetColors()
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:palette", WithWriter(&buf), WithAllowed("test:*"), WithColor(true))
+		SetColor("test:palette", 45)
+		k.Printf("test")
+
+		is.Contains(buf.String(), "\x1b[38;5;45mtest:palette \x1b[0m")
+	})
+
+	t.Run("should win over KEMBA_COLORS", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_COLORS", "test:palette=196")
+		defer func() {
+			_ = os.Unsetenv("KEMBA_COLORS")
+			ResetColors()
+		}()
+
+		SetColor("test:palette", 45)
+
+		is.Equal(color.C256(45).Value(), PickColor("test:palette").Value())
+	})
+}
+
+func Test_SetPalette(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should pick colors from the palette", func(t *testing.T) {
+		defer ResetColors()
+
+		SetPalette(PaletteColorblind)
+
+		for _, tag := range []string{"app", "app:db", "app:http", "kemba"} {
+			is.Contains(PaletteColorblind, PickColor(tag).Value(), tag)
+		}
+	})
+
+	t.Run("should index the palette with the checksum of the tag", func(t *testing.T) {
+		defer ResetColors()
+
+		SetPalette(PaletteDark)
+
+		sum := crc64.Checksum([]byte("test:kemba"), table)
+		is.Equal(PaletteDark[sum%uint64(len(PaletteDark))], PickColor("test:kemba").Value())
+	})
+
+	t.Run("should restore the default palette", func(t *testing.T) {
+		SetPalette(PaletteLight)
+		SetPalette(nil)
+
+		is.Equal(color.C256(81).Value(), PickColor("test:kemba").Value())
+	})
+
+	t.Run("should select the palette from KEMBA_PALETTE", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_PALETTE", "dark")
+		defer func() {
+			_ = os.Unsetenv("KEMBA_PALETTE")
+			ResetColors()
+		}()
+		ResetColors()
+
+		for _, tag := range []string{"app", "app:db", "app:http", "kemba"} {
+			is.Contains(PaletteDark, PickColor(tag).Value(), tag)
+		}
+	})
+}
+
+func Test_Private_pickedColors(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should keep the cached colors when the patterns change", func(t *testing.T) {
+		defer registry.set(nil)
+
+		_ = PickColor("test:cached")
+		Enable("test:*")
+
+		_, ok := pickedColors.Load("test:cached")
+		is.True(ok)
+	})
+
+	t.Run("should drop the cached colors when the colors change", func(t *testing.T) {
+		defer ResetColors()
+
+		_ = PickColor("test:cached")
+		SetColor("test:other", 45)
+
+		_, ok := pickedColors.Load("test:cached")
+		is.False(ok)
+	})
+}
+
+func Test_Private_getColorsFromEnv(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should parse the assignments", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_COLORS", "db=196, http = 45,bad,color=256,=1")
+		defer func() { _ = os.Unsetenv("KEMBA_COLORS") }()
+
+		is.Equal([]colorOverride{{"db", 196}, {"http", 45}}, getColorsFromEnv())
+	})
+
+	t.Run("should assign the colors to matching tags", func(t *testing.T) {
+		_ = os.Setenv("KEMBA_COLORS", "db=196,http=45")
+		defer func() {
+			_ = os.Unsetenv("KEMBA_COLORS")
+			ResetColors()
+		}()
+		ResetColors()
+
+		is.Equal(color.C256(196).Value(), PickColor("db").Value())
+		is.Equal(color.C256(45).Value(), PickColor("http").Value())
+	})
+}
+
+func Benchmark_pickColor(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = pickColor("test:kemba")
+	}
+}
@@ -0,0 +1,213 @@
+package kemba
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// loggerRegistry holds the namespace patterns, output and clock shared by every Kemba logger.
+// Loggers are not tracked: each change increments the generation, and a logger re-evaluates its
+// state the next time it is used when its generation is behind, so that loggers can be collected.
+type loggerRegistry struct {
+	mu        sync.Mutex
+	gen       atomic.Uint64
+	clockGen  uint64
+	clockSet  time.Time
+	explained map[string]bool
+	patterns  *string
+	redirect  *redirect
+	clock     Clock
+}
+
+// redirect routes the output of loggers that were not created with WithWriter.
+type redirect struct {
+	writer io.Writer
+	format *Format
+}
+
+var registry = &loggerRegistry{clock: systemClock}
+
+// add will evaluate the state of a new logger. When requested with KEMBA_EXPLAIN, the decision
+// is printed for the first logger of each tag, once the lock is released.
+func (r *loggerRegistry) add(k *core) {
+	r.mu.Lock()
+
+	k.mu.Lock()
+	r.evaluate(k)
+	k.mu.Unlock()
+
+	var e *Explanation
+	if explainEnabled() && !r.explained[k.tag] {
+		if r.explained == nil {
+			r.explained = make(map[string]bool)
+		}
+		r.explained[k.tag] = true
+
+		ex := explain(k.tag, r.sources(k))
+		e = &ex
+	}
+
+	r.mu.Unlock()
+
+	if e != nil {
+		k.printExplanation(*e)
+	}
+}
+
+// evaluate will update the logger with the active patterns, output and clock. Loggers created
+// without WithClock restart their time delta from the time the clock was set, when it was set
+// since they were last evaluated.
+//
+// The caller must hold the lock, and the write lock of the logger.
+func (r *loggerRegistry) evaluate(k *core) {
+	restart := k.gen < r.clockGen
+
+	k.update(r.allowedFor(k), r.redirect, r.clock)
+	if restart && k.cfg.clock == nil {
+		k.last = r.clockSet
+	}
+	k.gen = r.gen.Load()
+}
+
+// set will replace the active patterns and re-evaluate every logger.
+// It returns the previously active patterns.
+func (r *loggerRegistry) set(patterns *string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.active()
+	r.patterns = patterns
+	r.update()
+
+	return prev
+}
+
+// refresh will re-evaluate every logger with the active patterns.
+func (r *loggerRegistry) refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.update()
+}
+
+// update will drop the cached matchers and increment the generation, so that every logger is
+// re-evaluated the next time it is used.
+//
+// The caller must hold the lock.
+func (r *loggerRegistry) update() {
+	matchers.reset()
+	r.gen.Add(1)
+}
+
+// active returns the patterns set with Enable, or the value of the environment when none were set.
+//
+// The caller must hold the lock.
+func (r *loggerRegistry) active() string {
+	if r.patterns != nil {
+		return *r.patterns
+	}
+	return getDebugFlagFromEnv()
+}
+
+// allowedFor returns the patterns that apply to the logger. Patterns provided with
+// WithAllowed take precedence over the active patterns.
+//
+// The caller must hold the lock.
+func (r *loggerRegistry) allowedFor(k *core) string {
+	if k.cfg.allowed != nil {
+		return *k.cfg.allowed
+	}
+	return r.active()
+}
+
+// sources returns the patterns that apply to the logger, by where they were read from.
+// When the logger is nil, the active patterns are returned.
+//
+// The caller must hold the lock.
+func (r *loggerRegistry) sources(k *core) []patternSource {
+	switch {
+	case k != nil && k.cfg.allowed != nil:
+		return []patternSource{{name: "WithAllowed", patterns: *k.cfg.allowed}}
+	case r.patterns != nil:
+		return []patternSource{{name: "Enable", patterns: *r.patterns}}
+	default:
+		return envSources()
+	}
+}
+
+// Enable will replace the namespace patterns read from the DEBUG and KEMBA environment variables
+// and re-evaluate every logger that has been created, as well as any created afterwards.
+//
+// Example:
+//
+//	kemba.Enable("app:*,-app:poller")
+func Enable(patterns string) {
+	registry.set(&patterns)
+}
+
+// Disable will disable every logger and return the patterns that were active,
+// so they can later be restored with Enable.
+func Disable() string {
+	empty := ""
+	return registry.set(&empty)
+}
+
+// Enabled reports if a logger with the provided tag would be enabled by the active patterns.
+func Enabled(tag string) bool {
+	registry.mu.Lock()
+	allowed := registry.active()
+	registry.mu.Unlock()
+
+	return allowed != "" && determineEnabled(tag, allowed)
+}
+
+// Redirect will enable the patterns and route the output of every logger that was not created
+// with WithWriter to w, rendered in the provided format, for loggers that have been created as
+// well as any created afterwards. The returned function restores the previous patterns and output.
+//
+// It is meant for tests, which can use the kembatest package in place of calling it directly.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	restore := kemba.Redirect("app:*", &buf, kemba.FormatText)
+//	defer restore()
+func Redirect(patterns string, w io.Writer, f Format) func() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	prevPatterns, prevRedirect := registry.patterns, registry.redirect
+	registry.patterns = &patterns
+	registry.redirect = &redirect{writer: w, format: &f}
+	registry.update()
+
+	return func() {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+
+		registry.patterns, registry.redirect = prevPatterns, prevRedirect
+		registry.update()
+	}
+}
+
+// SetClock will make every logger that was not created with WithClock read the time from the
+// clock, restarting their time deltas. A nil clock restores time.Now. It returns the previous clock.
+//
+// It is meant for tests, such as with the fake clock of the kembatest package.
+func SetClock(c Clock) Clock {
+	if c == nil {
+		c = systemClock
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	prev := registry.clock
+	registry.clock = c
+	registry.update()
+	registry.clockGen, registry.clockSet = registry.gen.Load(), c.Now()
+
+	return prev
+}
@@ -0,0 +1,185 @@
+package kemba
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Enable(t *testing.T) {
+	is := assert.New(t)
+	defer registry.set(nil)
+
+	t.Run("should enable existing loggers", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithColor(false))
+		k.Printf("hidden")
+		is.False(k.enabled, "Logger should NOT be enabled")
+
+		Enable("test:*")
+		k.Printf("visible")
+
+		is.True(k.isEnabled(), "Logger should be enabled")
+		is.Equal("test:*", k.allowed)
+		is.Regexp(`^test:kemba visible \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should enable loggers created afterwards", func(t *testing.T) {
+		Enable("test:*")
+
+		k := New("test:kemba")
+		is.True(k.enabled, "Logger should be enabled")
+	})
+
+	t.Run("should take precedence over the environment", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*")
+
+		Enable("other:*")
+		k := New("test:kemba")
+		is.False(k.enabled, "Logger should NOT be enabled")
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should not affect loggers created with WithAllowed", func(t *testing.T) {
+		k := NewWithOptions("test:kemba", WithAllowed("test:*"))
+
+		Enable("other:*")
+		is.True(k.isEnabled(), "Logger should be enabled")
+	})
+}
+
+func Test_Disable(t *testing.T) {
+	is := assert.New(t)
+	defer registry.set(nil)
+
+	t.Run("should disable existing loggers and return the active patterns", func(t *testing.T) {
+		var buf bytes.Buffer
+		Enable("test:*")
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithColor(false))
+
+		prev := Disable()
+		k.Printf("hidden")
+
+		is.Equal("test:*", prev)
+		is.False(k.enabled, "Logger should NOT be enabled")
+		is.Equal("", buf.String())
+
+		Enable(prev)
+		is.True(k.isEnabled(), "Logger should be enabled")
+	})
+
+	t.Run("should return the environment patterns when Enable was not called", func(t *testing.T) {
+		registry.set(nil)
+		_ = os.Setenv("DEBUG", "test:*")
+
+		is.Equal("test:*", Disable())
+
+		_ = os.Setenv("DEBUG", "")
+	})
+}
+
+func Test_Enabled(t *testing.T) {
+	is := assert.New(t)
+	defer registry.set(nil)
+
+	t.Run("should report on the environment", func(t *testing.T) {
+		_ = os.Setenv("DEBUG", "test:*,-test:skip")
+
+		is.True(Enabled("test:kemba"))
+		is.False(Enabled("test:skip"))
+		is.False(Enabled("other"))
+
+		_ = os.Setenv("DEBUG", "")
+	})
+
+	t.Run("should report on the enabled patterns", func(t *testing.T) {
+		Enable("other")
+
+		is.True(Enabled("other"))
+		is.False(Enabled("test:kemba"))
+	})
+
+	t.Run("should report false when disabled", func(t *testing.T) {
+		Disable()
+
+		is.False(Enabled("other"))
+	})
+}
+
+func Test_Redirect(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should route existing loggers and restore them", func(t *testing.T) {
+		k := New("test:redirect")
+		is.False(k.isEnabled())
+
+		var buf bytes.Buffer
+		restore := Redirect("test:*", &buf, FormatJSON)
+		k.Printf("test")
+
+		is.Regexp(`^\{"time":"[^"]+","ns":"test:redirect","msg":"test","delta_ms":\d+\}\n$`, buf.String())
+
+		restore()
+		is.False(k.isEnabled())
+		is.Equal(os.Stderr, k.out)
+	})
+}
+
+func Test_Private_registry(t *testing.T) {
+	is := assert.New(t)
+
+	collect := func(n int, create func(i int) *Kemba) int32 {
+		var collected int32
+		for i := 0; i < n; i++ {
+			runtime.SetFinalizer(create(i), func(*Kemba) { atomic.AddInt32(&collected, 1) })
+		}
+
+		for i := 0; i < 20 && atomic.LoadInt32(&collected) < int32(n); i++ {
+			runtime.GC()
+			time.Sleep(time.Millisecond)
+		}
+		return atomic.LoadInt32(&collected)
+	}
+
+	t.Run("should collect discarded loggers", func(t *testing.T) {
+		is.Equal(int32(100), collect(100, func(i int) *Kemba {
+			return NewWithOptions(fmt.Sprintf("test:registry:%d", i), WithWriter(&bytes.Buffer{}), WithAllowed("test:*"))
+		}))
+	})
+
+	t.Run("should collect discarded derived loggers", func(t *testing.T) {
+		k := NewWithOptions("test:registry", WithWriter(&bytes.Buffer{}), WithAllowed("test:*"))
+
+		is.Equal(int32(100), collect(100, func(i int) *Kemba {
+			return k.With("req", i)
+		}))
+		is.Equal(int32(100), collect(100, func(i int) *Kemba {
+			return k.Extend(fmt.Sprintf("%d", i))
+		}))
+		is.Equal(int32(100), collect(100, func(i int) *Kemba {
+			return k.Handler().WithGroup(fmt.Sprintf("%d", i)).(*Handler).k
+		}))
+	})
+
+	t.Run("should re-evaluate loggers when they are used", func(t *testing.T) {
+		defer registry.set(nil)
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:registry", WithWriter(&buf), WithColor(false))
+		gen := k.gen
+
+		Enable("test:*")
+		is.Equal(gen, k.gen)
+
+		k.Printf("visible")
+		is.NotEqual(gen, k.gen)
+		is.Regexp(`^test:registry visible \+\d+\S+\n$`, buf.String())
+	})
+}
@@ -0,0 +1,116 @@
+package kemba
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that writes records through a Kemba logger.
+//
+// The tag of the logger is extended with the name of every group, so that the
+// DEBUG and KEMBA patterns gate records by namespace. Attributes are rendered as fields.
+type Handler struct {
+	k *Kemba
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a slog.Handler for the provided tag, configured with the provided options.
+//
+// Example:
+//
+//	logger := slog.New(kemba.NewHandler("app"))
+//	logger.WithGroup("db").Info("connected", "pool", 4)
+//
+// Output:
+//
+//	app:db connected level=INFO pool=4 +0s
+func NewHandler(tag string, opts ...Option) *Handler {
+	return &Handler{k: NewWithOptions(tag, opts...)}
+}
+
+// Handler returns a slog.Handler that writes records through the logger.
+func (k *Kemba) Handler() *Handler {
+	return &Handler{k: k}
+}
+
+// Enabled reports if the tag of the handler is enabled, or if the flight recorder is active.
+// The level is not considered.
+func (h *Handler) Enabled(_ context.Context, _ slog.Level) bool {
+	return h.k.isEnabled() || flight.active.Load()
+}
+
+// Handle will log the message of the record followed by its level and attributes.
+// The caller annotation, when enabled, uses the program counter of the record.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	recording := flight.active.Load()
+	if recording || h.k.isEnabled() {
+		if t := h.k.testingTB(); t != nil {
+			t.Helper()
+		}
+
+		fields := make([]field, 0, len(h.k.fields)+r.NumAttrs()+1)
+		fields = append(fields, h.k.fields...)
+		fields = append(fields, field{key: slog.LevelKey, value: r.Level})
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendAttr(fields, "", a)
+			return true
+		})
+
+		lines := messageLines(r.Message)
+		if recording {
+			h.k.recordLines(lines, fields)
+		}
+		h.k.logPC(r.PC, lines, fields)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a new Handler whose logger carries the provided attributes as fields.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make([]field, 0, len(h.k.fields)+len(attrs))
+	fields = append(fields, h.k.fields...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, "", a)
+	}
+
+	return &Handler{k: &Kemba{core: h.k.core, fields: fields}}
+}
+
+// WithGroup returns a new Handler whose logger tag is extended with the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &Handler{k: h.k.Extend(name)}
+}
+
+// appendAttr will append the attribute as a field, flattening groups into dot delimited keys.
+func appendAttr(fields []field, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, key, ga)
+		}
+		return fields
+	}
+
+	return append(fields, field{key: key, value: a.Value.Any()})
+}
@@ -0,0 +1,62 @@
+package kemba
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Handler(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should render records with the tag prefix and delta", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false)))
+		logger.Info("connected", "pool", 4)
+
+		is.Regexp(`^test:kemba connected level=INFO pool=4 \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should render the attributes of an empty message", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false)))
+		logger.Info("", "pool", 4)
+
+		is.Regexp(`^test:kemba  level=INFO pool=4 \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should map groups to extended tags", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler("test", WithWriter(&buf), WithAllowed("test:db"), WithColor(false)))
+		logger.Info("hidden")
+		logger.WithGroup("db").Debug("visible")
+
+		is.Regexp(`^test:db visible level=DEBUG \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should consult the namespace patterns", func(t *testing.T) {
+		h := NewHandler("test:kemba", WithAllowed("test:*,-test:kemba:skip"))
+
+		is.True(h.Enabled(context.Background(), slog.LevelDebug))
+		is.False(h.WithGroup("skip").Enabled(context.Background(), slog.LevelError))
+	})
+
+	t.Run("should render attributes as fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false)))
+		logger.With("id", 1337).Warn("done", slog.Group("req", "method", "GET", "path", "/"))
+
+		is.Regexp(`^test:kemba done id=1337 level=WARN req.method=GET req.path=/ \+\d+\S+\n$`, buf.String())
+	})
+
+	t.Run("should be created from an existing logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		slog.New(k.Handler()).Info("test")
+
+		is.Regexp(`^test:kemba test level=INFO \+\d+\S+\n$`, buf.String())
+	})
+}
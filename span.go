@@ -0,0 +1,161 @@
+package kemba
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// spanIndent is the indentation of each level of nested spans.
+const spanIndent = "  "
+
+// Timing is the aggregated duration of the spans with the same label.
+type Timing struct {
+	Label string
+	Count int
+	Total time.Duration
+	Min   time.Duration
+	Max   time.Duration
+}
+
+// String returns the summary of the timing.
+func (t Timing) String() string {
+	return fmt.Sprintf("%s: %d calls, total %s, min %s, max %s", t.Label, t.Count, t.Total, t.Min, t.Max)
+}
+
+// spans holds the active nesting levels and the timings of the spans of a logger.
+type spans struct {
+	active  []bool
+	labels  []string
+	timings map[string]*Timing
+}
+
+// Time will log the start of a span and return the function ending it, which logs the measured
+// duration. Spans started before the previous one ended are nested, and their lines indented.
+// The durations are aggregated by label, see Timings.
+//
+// Nesting assumes the spans of a logger, and of the loggers derived from it with With, are
+// started from one goroutine. Spans of concurrent goroutines are measured and aggregated
+// correctly, but indented as if nested in each other.
+//
+// When the logger is disabled nothing is measured, and the returned function does nothing, unless
+// the flight recorder is active, which records the start and duration of the span.
+//
+// Example:
+//
+//	defer k.Time("load config")()
+//
+// Output:
+//
+//	app load config...
+//	app load config took 12.5ms
+func (k *Kemba) Time(label string) func() {
+	if k.core == nil {
+		return func() {}
+	}
+	if !k.isEnabled() {
+		if flight.active.Load() {
+			return k.recordSpan(label)
+		}
+		return func() {}
+	}
+	if t := k.testingTB(); t != nil {
+		t.Helper()
+	}
+
+	k.mu.Lock()
+	start := k.clock.Now()
+	depth := k.spans.start()
+	k.mu.Unlock()
+
+	indent := strings.Repeat(spanIndent, depth)
+
+	lines := []string{indent + label + "..."}
+	if flight.active.Load() {
+		k.recordLines(lines, k.fields)
+	}
+	k.log(lines, k.fields)
+
+	stopped := false
+	return func() {
+		if t := k.testingTB(); t != nil {
+			t.Helper()
+		}
+
+		k.mu.Lock()
+		if stopped {
+			k.mu.Unlock()
+			return
+		}
+		stopped = true
+		d := k.clock.Now().Sub(start)
+		k.spans.stop(depth)
+		k.spans.add(label, d)
+		k.mu.Unlock()
+
+		if trace.active.Load() {
+			trace.add(timelineEvent{tag: k.tag, name: label, time: start, dur: d, span: true, fields: k.fields})
+		}
+
+		lines := []string{fmt.Sprintf("%s%s took %s", indent, label, d)}
+		if flight.active.Load() {
+			k.recordLines(lines, k.fields)
+		}
+		k.log(lines, k.fields)
+	}
+}
+
+// Timings returns the aggregated durations of the spans ended with the logger, by label in
+// the order they were first ended.
+func (k *Kemba) Timings() []Timing {
+	if k.core == nil {
+		return nil
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	timings := make([]Timing, 0, len(k.spans.labels))
+	for _, l := range k.spans.labels {
+		timings = append(timings, *k.spans.timings[l])
+	}
+	return timings
+}
+
+// start will open a span nested in the active spans and return its depth.
+func (s *spans) start() int {
+	s.active = append(s.active, true)
+	return len(s.active) - 1
+}
+
+// stop will close the span at the provided depth. The depth of the next span follows the
+// deepest span still active, even when spans are not closed in the reverse order of their start.
+func (s *spans) stop(depth int) {
+	s.active[depth] = false
+	for len(s.active) > 0 && !s.active[len(s.active)-1] {
+		s.active = s.active[:len(s.active)-1]
+	}
+}
+
+// add will aggregate the duration of a span.
+func (s *spans) add(label string, d time.Duration) {
+	if s.timings == nil {
+		s.timings = make(map[string]*Timing)
+	}
+
+	t, ok := s.timings[label]
+	if !ok {
+		t = &Timing{Label: label, Min: d, Max: d}
+		s.timings[label] = t
+		s.labels = append(s.labels, label)
+	}
+
+	t.Count++
+	t.Total += d
+	if d < t.Min {
+		t.Min = d
+	}
+	if d > t.Max {
+		t.Max = d
+	}
+}
@@ -0,0 +1,143 @@
+package kemba
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Time(t *testing.T) {
+	is := assert.New(t)
+
+	newTimed := func(buf *bytes.Buffer) (*Kemba, *time.Time) {
+		now := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+		clock := ClockFunc(func() time.Time { return now })
+		k := NewWithOptions("test:kemba", WithWriter(buf), WithAllowed("test:*"), WithColor(false), WithClock(clock), WithTime(TimeNone))
+		return k, &now
+	}
+
+	t.Run("should log the start and the duration of a span", func(t *testing.T) {
+		var buf bytes.Buffer
+		k, now := newTimed(&buf)
+
+		stop := k.Time("load config")
+		*now = now.Add(12500 * time.Microsecond)
+		stop()
+
+		is.Equal("test:kemba load config...\ntest:kemba load config took 12.5ms\n", buf.String())
+	})
+
+	t.Run("should indent nested spans", func(t *testing.T) {
+		var buf bytes.Buffer
+		k, now := newTimed(&buf)
+
+		func() {
+			defer k.Time("outer")()
+			*now = now.Add(time.Millisecond)
+			func() {
+				defer k.Time("inner")()
+				*now = now.Add(2 * time.Millisecond)
+			}()
+		}()
+
+		is.Equal(`test:kemba outer...
+test:kemba   inner...
+test:kemba   inner took 2ms
+test:kemba outer took 3ms
+`, buf.String())
+	})
+
+	t.Run("should follow the active spans when ended out of order", func(t *testing.T) {
+		var buf bytes.Buffer
+		k, _ := newTimed(&buf)
+
+		first := k.Time("first")
+		second := k.Time("second")
+		first()
+		third := k.Time("third")
+		second()
+		third()
+		k.Time("fourth")()
+
+		is.Equal(`test:kemba first...
+test:kemba   second...
+test:kemba first took 0s
+test:kemba     third...
+test:kemba   second took 0s
+test:kemba     third took 0s
+test:kemba fourth...
+test:kemba fourth took 0s
+`, buf.String())
+	})
+
+	t.Run("should measure the spans of concurrent goroutines", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false), WithTime(TimeNone))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					k.With("worker", j).Time("work")()
+				}
+			}()
+		}
+		wg.Wait()
+
+		timings := k.Timings()
+		is.Len(timings, 1)
+		is.Equal(800, timings[0].Count)
+		is.Equal(1600, bytes.Count(buf.Bytes(), []byte("\n")))
+
+		buf.Reset()
+		k.Time("after")()
+		is.Regexp(`^test:kemba after\.\.\.\ntest:kemba after took \S+\n$`, buf.String())
+	})
+
+	t.Run("should only end a span once", func(t *testing.T) {
+		var buf bytes.Buffer
+		k, _ := newTimed(&buf)
+
+		stop := k.Time("once")
+		stop()
+		stop()
+
+		is.Equal("test:kemba once...\ntest:kemba once took 0s\n", buf.String())
+		is.Len(k.Timings(), 1)
+	})
+
+	t.Run("should aggregate the durations by label", func(t *testing.T) {
+		var buf bytes.Buffer
+		k, now := newTimed(&buf)
+
+		for _, d := range []time.Duration{5, 15, 10} {
+			stop := k.Time("query")
+			*now = now.Add(d * time.Millisecond)
+			stop()
+		}
+		stop := k.Time("render")
+		*now = now.Add(time.Millisecond)
+		stop()
+
+		is.Equal([]Timing{
+			{Label: "query", Count: 3, Total: 30 * time.Millisecond, Min: 5 * time.Millisecond, Max: 15 * time.Millisecond},
+			{Label: "render", Count: 1, Total: time.Millisecond, Min: time.Millisecond, Max: time.Millisecond},
+		}, k.Timings())
+		is.Equal("query: 3 calls, total 30ms, min 5ms, max 15ms", k.Timings()[0].String())
+	})
+
+	t.Run("should do nothing when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed(""))
+
+		k.Time("disabled")()
+
+		is.Equal("", buf.String())
+		is.Empty(k.Timings())
+	})
+}
@@ -0,0 +1,58 @@
+package kemba
+
+import (
+	"bytes"
+	"log"
+)
+
+// stdWriter is an io.Writer emitting each write of a log.Logger as a log event.
+type stdWriter struct {
+	k *Kemba
+}
+
+// Write will emit the lines of the message as a single log event, or discard them when the
+// logger is disabled and the flight recorder is not active.
+func (w stdWriter) Write(p []byte) (int, error) {
+	recording := flight.active.Load()
+	if recording || w.k.isEnabled() {
+		lines := scanLines(bytes.NewReader(p))
+		if recording {
+			w.k.recordLines(lines, w.k.fields)
+		}
+		w.k.logPC(0, lines, w.k.fields)
+	}
+	return len(p), nil
+}
+
+// StdLogger returns a *log.Logger writing through the logger, for dependencies that accept one
+// such as http.Server.ErrorLog. Each message is a log event rendered with the tag, colors and
+// time delta of the logger, and is discarded when the logger is disabled, unless the flight
+// recorder is active.
+//
+// Example:
+//
+//	srv := &http.Server{ErrorLog: kemba.New("app:http").StdLogger()}
+func (k *Kemba) StdLogger() *log.Logger {
+	return log.New(stdWriter{k: k}, "", 0)
+}
+
+// RedirectStdLog will route the output of the global log package through a logger with the
+// provided tag, and returns a function restoring the previous output, prefix and flags.
+//
+// Example:
+//
+//	restore := kemba.RedirectStdLog("app:log")
+//	defer restore()
+func RedirectStdLog(tag string) func() {
+	flags, prefix, w := log.Flags(), log.Prefix(), log.Writer()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(stdWriter{k: New(tag)})
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(w)
+	}
+}
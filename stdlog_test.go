@@ -0,0 +1,49 @@
+package kemba
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StdLogger(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should write through the logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		l := k.StdLogger()
+		l.Printf("key: %s", "test")
+		l.Print("first\nsecond")
+
+		is.Regexp(`^test:kemba key: test \+\S+\ntest:kemba first \+\S+\ntest:kemba second\n$`, buf.String())
+	})
+
+	t.Run("should discard the output when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed(""))
+		k.StdLogger().Println("test")
+
+		is.Equal("", buf.String())
+	})
+}
+
+func Test_RedirectStdLog(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should route and restore the global log package", func(t *testing.T) {
+		var buf bytes.Buffer
+		defer Redirect("test:*", &buf, FormatText)()
+
+		restore := RedirectStdLog("test:stdlog")
+		log.Printf("key: %s", "test")
+		is.Regexp(`^test:stdlog key: test \+\S+\n$`, buf.String())
+
+		restore()
+		is.Equal(log.LstdFlags, log.Flags())
+		is.Equal(os.Stderr, log.Writer())
+	})
+}
@@ -0,0 +1,80 @@
+package kemba
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeMode controls the time information added to text output.
+type TimeMode int
+
+const (
+	// TimeAuto appends the time delta when the writer is a terminal, and prepends an ISO 8601
+	// timestamp when the writer is a file or pipe that is not a terminal.
+	TimeAuto TimeMode = iota
+	// TimeDelta appends the time delta since the last log event, ex. +12ms
+	TimeDelta
+	// TimeISO prepends an ISO 8601 timestamp, ex. 2020-07-27T00:00:00.012Z
+	TimeISO
+	// TimeUnix prepends a Unix timestamp with millisecond precision, ex. 1595808000.012
+	TimeUnix
+	// TimeBoth prepends an ISO 8601 timestamp and appends the time delta.
+	TimeBoth
+	// TimeNone omits all time information.
+	TimeNone
+)
+
+// timeModes maps the KEMBA_TIME env values to time modes.
+var timeModes = map[string]TimeMode{
+	"auto":  TimeAuto,
+	"delta": TimeDelta,
+	"iso":   TimeISO,
+	"unix":  TimeUnix,
+	"both":  TimeBoth,
+	"none":  TimeNone,
+}
+
+// showDelta reports if the time delta is appended.
+func (m TimeMode) showDelta() bool {
+	return m == TimeDelta || m == TimeBoth
+}
+
+// formatTimestamp will render the timestamp prepended to each line, or an empty string
+// when the mode does not prepend one.
+func (m TimeMode) formatTimestamp(t time.Time) string {
+	switch m {
+	case TimeISO, TimeBoth:
+		return t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+	case TimeUnix:
+		return fmt.Sprintf("%d.%03d", t.Unix(), t.Nanosecond()/int(time.Millisecond))
+	default:
+		return ""
+	}
+}
+
+// resolveTimeMode will replace TimeAuto with the mode that suits the writer.
+func resolveTimeMode(mode TimeMode, w io.Writer) TimeMode {
+	if mode != TimeAuto {
+		return mode
+	}
+
+	if f, ok := w.(*os.File); ok && !isTerminal(f) {
+		return TimeISO
+	}
+	return TimeDelta
+}
+
+// isTerminal reports if the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// getTimeModeFromEnv will read the time mode from the KEMBA_TIME env value, or the configured Env.Time.
+// Unknown values fall back to TimeAuto.
+func getTimeModeFromEnv() TimeMode {
+	return timeModes[strings.ToLower(getenv(currentEnv().Time))]
+}
@@ -0,0 +1,79 @@
+package kemba
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TimeMode(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	newLogger := func(buf *bytes.Buffer, mode TimeMode) *Kemba {
+		return NewWithOptions("test:kemba", WithWriter(buf), WithAllowed("test:*"), WithColor(false), WithClock(ClockFunc(clock)), WithTime(mode))
+	}
+
+	tests := []struct {
+		mode     TimeMode
+		expected string
+	}{
+		{TimeDelta, "test:kemba first +12ms\ntest:kemba second\n"},
+		{TimeISO, "2020-07-27T00:00:00.012Z test:kemba first\n2020-07-27T00:00:00.012Z test:kemba second\n"},
+		{TimeUnix, "1595808000.012 test:kemba first\n1595808000.012 test:kemba second\n"},
+		{TimeBoth, "2020-07-27T00:00:00.012Z test:kemba first +12ms\n2020-07-27T00:00:00.012Z test:kemba second\n"},
+		{TimeNone, "test:kemba first\ntest:kemba second\n"},
+	}
+
+	for _, tt := range tests {
+		now = time.Date(2020, 7, 27, 0, 0, 0, 0, time.UTC)
+
+		var buf bytes.Buffer
+		k := newLogger(&buf, tt.mode)
+		now = now.Add(12 * time.Millisecond)
+		k.Printf("first\nsecond")
+
+		is.Equal(tt.expected, buf.String())
+	}
+
+	t.Run("should use deltas for writers that are not files", func(t *testing.T) {
+		is.Equal(TimeDelta, resolveTimeMode(TimeAuto, &bytes.Buffer{}))
+	})
+
+	t.Run("should use ISO timestamps for files that are not terminals", func(t *testing.T) {
+		r, w, _ := os.Pipe()
+		defer func() {
+			_ = r.Close()
+			_ = w.Close()
+		}()
+
+		is.Equal(TimeISO, resolveTimeMode(TimeAuto, w))
+	})
+
+	t.Run("should keep an explicit mode", func(t *testing.T) {
+		r, w, _ := os.Pipe()
+		defer func() {
+			_ = r.Close()
+			_ = w.Close()
+		}()
+
+		is.Equal(TimeDelta, resolveTimeMode(TimeDelta, w))
+	})
+
+	t.Run("should read the mode from KEMBA_TIME", func(t *testing.T) {
+		defer func() { _ = os.Setenv("KEMBA_TIME", "delta") }()
+
+		for value, mode := range timeModes {
+			_ = os.Setenv("KEMBA_TIME", value)
+			is.Equal(mode, getTimeModeFromEnv())
+		}
+
+		_ = os.Setenv("KEMBA_TIME", "unknown")
+		is.Equal(TimeAuto, getTimeModeFromEnv())
+	})
+}
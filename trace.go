@@ -0,0 +1,166 @@
+package kemba
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxTraceEvents is the number of events the timeline holds, after which events are dropped.
+const maxTraceEvents = 1 << 16
+
+// timeline records the log events and spans of enabled loggers while tracing.
+type timeline struct {
+	active  atomic.Bool
+	mu      sync.Mutex
+	events  []timelineEvent
+	dropped int
+}
+
+// timelineEvent is a log event, or a span when it has a duration.
+type timelineEvent struct {
+	tag    string
+	name   string
+	time   time.Time
+	dur    time.Duration
+	span   bool
+	fields []field
+}
+
+// traceEvent is an event of the Chrome trace event format.
+type traceEvent struct {
+	Name string                 `json:"name"`
+	Ph   string                 `json:"ph"`
+	TS   float64                `json:"ts"`
+	Dur  float64                `json:"dur,omitempty"`
+	PID  int                    `json:"pid"`
+	TID  int                    `json:"tid"`
+	S    string                 `json:"s,omitempty"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// traceFile is the JSON object format of a Chrome trace.
+type traceFile struct {
+	TraceEvents     []traceEvent           `json:"traceEvents"`
+	DisplayTimeUnit string                 `json:"displayTimeUnit"`
+	OtherData       map[string]interface{} `json:"otherData,omitempty"`
+}
+
+var trace = &timeline{}
+
+// StartTrace will record the log events and spans of enabled loggers into an in-memory timeline,
+// until StopTrace is called. Events recorded by a previous trace are dropped. The timeline holds
+// up to 65536 events, after which events are dropped.
+//
+// Example:
+//
+//	kemba.StartTrace()
+//	defer func() {
+//		kemba.StopTrace()
+//		f, _ := os.Create("trace.json")
+//		defer f.Close()
+//		_ = kemba.WriteTrace(f)
+//	}()
+func StartTrace() {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+
+	trace.events = nil
+	trace.dropped = 0
+	trace.active.Store(true)
+}
+
+// StopTrace will stop recording events into the timeline.
+func StopTrace() {
+	trace.active.Store(false)
+}
+
+// WriteTrace will write the timeline in the Chrome trace event format, which can be loaded in
+// chrome://tracing or https://ui.perfetto.dev. Each tag is a track, where log events are instant
+// events with their fields as arguments, and spans are complete events.
+func WriteTrace(w io.Writer) error {
+	trace.mu.Lock()
+	events := append([]timelineEvent(nil), trace.events...)
+	dropped := trace.dropped
+	trace.mu.Unlock()
+
+	pid := os.Getpid()
+	tids := make(map[string]int)
+	out := traceFile{TraceEvents: []traceEvent{}, DisplayTimeUnit: "ms"}
+
+	for _, e := range events {
+		tid, ok := tids[e.tag]
+		if !ok {
+			tid = len(tids) + 1
+			tids[e.tag] = tid
+			out.TraceEvents = append(out.TraceEvents, traceEvent{
+				Name: "thread_name",
+				Ph:   "M",
+				PID:  pid,
+				TID:  tid,
+				Args: map[string]interface{}{"name": e.tag},
+			})
+		}
+
+		te := traceEvent{Name: e.name, Ph: "i", S: "t", TS: micros(time.Duration(e.time.UnixNano())), PID: pid, TID: tid}
+		if e.span {
+			te.Ph, te.S, te.Dur = "X", "", micros(e.dur)
+		}
+		if len(e.fields) > 0 {
+			te.Args = make(map[string]interface{}, len(e.fields))
+			for _, f := range e.fields {
+				te.Args[f.key] = jsonValue(f.value)
+			}
+		}
+		out.TraceEvents = append(out.TraceEvents, te)
+	}
+
+	if dropped > 0 {
+		out.OtherData = map[string]interface{}{"dropped_events": dropped}
+	}
+
+	return json.NewEncoder(w).Encode(out)
+}
+
+// add will record the event, unless the timeline is full.
+func (t *timeline) add(e timelineEvent) {
+	e.fields = freezeTraceFields(e.fields)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.events) >= maxTraceEvents {
+		t.dropped++
+		return
+	}
+	t.events = append(t.events, e)
+}
+
+// freezeTraceFields returns a copy of the fields, with the values that are not strings or scalars
+// encoded to JSON, so that the timeline never holds a reference to the values of the caller.
+func freezeTraceFields(fields []field) []field {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	frozen := make([]field, len(fields))
+	for i, f := range fields {
+		v, ok := freezeValue(f.value)
+		if !ok {
+			v = jsonValue(f.value)
+			if b, err := json.Marshal(v); err == nil {
+				v = json.RawMessage(b)
+			}
+		}
+		frozen[i] = field{key: f.key, value: v}
+	}
+	return frozen
+}
+
+// micros returns the duration in microseconds, the time unit of the trace event format.
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
@@ -0,0 +1,113 @@
+package kemba
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WriteTrace(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should write one track per tag", func(t *testing.T) {
+		now := time.Unix(1595808000, 0)
+		clock := ClockFunc(func() time.Time { return now })
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed("test:**"), WithClock(clock))
+		db := k.Extend("db")
+
+		StartTrace()
+		k.With("id", 1).Printf("first\nsecond")
+		stop := db.Time("query")
+		now = now.Add(1500 * time.Microsecond)
+		stop()
+		k.Printf("")
+		StopTrace()
+		k.Printf("not traced")
+
+		var buf bytes.Buffer
+		is.NoError(WriteTrace(&buf))
+
+		var out traceFile
+		is.NoError(json.Unmarshal(buf.Bytes(), &out))
+
+		pid := os.Getpid()
+		ts := 1595808000e6
+		is.Equal([]traceEvent{
+			{Name: "thread_name", Ph: "M", PID: pid, TID: 1, Args: map[string]interface{}{"name": "test:kemba"}},
+			{Name: "first", Ph: "i", S: "t", TS: ts, PID: pid, TID: 1, Args: map[string]interface{}{"id": float64(1)}},
+			{Name: "thread_name", Ph: "M", PID: pid, TID: 2, Args: map[string]interface{}{"name": "test:kemba:db"}},
+			{Name: "query...", Ph: "i", S: "t", TS: ts, PID: pid, TID: 2},
+			{Name: "query", Ph: "X", TS: ts, Dur: 1500, PID: pid, TID: 2},
+			{Name: "query took 1.5ms", Ph: "i", S: "t", TS: ts + 1500, PID: pid, TID: 2},
+			{Name: "", Ph: "i", S: "t", TS: ts + 1500, PID: pid, TID: 1},
+		}, out.TraceEvents)
+		is.Equal("ms", out.DisplayTimeUnit)
+	})
+
+	t.Run("should record the field values when they are logged", func(t *testing.T) {
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed("test:*"), WithClock(ClockFunc(func() time.Time { return time.Unix(0, 0) })))
+		m := map[string]int{"a": 1}
+
+		StartTrace()
+		k.With("m", m, "id", 1).Printf("test")
+		StopTrace()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 2; i <= 100; i++ {
+				m["a"] = i
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			is.NoError(WriteTrace(&bytes.Buffer{}))
+		}
+		<-done
+
+		var out traceFile
+		var buf bytes.Buffer
+		is.NoError(WriteTrace(&buf))
+		is.NoError(json.Unmarshal(buf.Bytes(), &out))
+		is.Len(out.TraceEvents, 2)
+		is.Equal(map[string]interface{}{"m": map[string]interface{}{"a": float64(1)}, "id": float64(1)}, out.TraceEvents[1].Args)
+	})
+
+	t.Run("should not record disabled loggers", func(t *testing.T) {
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed(""))
+
+		StartTrace()
+		k.Printf("test")
+		k.Time("test")()
+		StopTrace()
+
+		var buf bytes.Buffer
+		is.NoError(WriteTrace(&buf))
+		is.JSONEq(`{"traceEvents":[],"displayTimeUnit":"ms"}`, buf.String())
+	})
+
+	t.Run("should drop events when the timeline is full", func(t *testing.T) {
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed("test:*"))
+
+		StartTrace()
+		for i := 0; i < maxTraceEvents+2; i++ {
+			k.Printf("test")
+		}
+		StopTrace()
+
+		var buf bytes.Buffer
+		is.NoError(WriteTrace(&buf))
+
+		var out traceFile
+		is.NoError(json.Unmarshal(buf.Bytes(), &out))
+		is.Len(out.TraceEvents, maxTraceEvents+1)
+		is.Equal(map[string]interface{}{"dropped_events": float64(2)}, out.OtherData)
+
+		StartTrace()
+		StopTrace()
+	})
+}
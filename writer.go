@@ -0,0 +1,99 @@
+package kemba
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxLineLength is the length at which a line without a newline is emitted as is,
+// so that the writer never buffers unbounded output.
+const maxLineLength = 64 * 1024
+
+// lineWriter is an io.WriteCloser emitting each line written to it through a logger.
+type lineWriter struct {
+	k      *Kemba
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+}
+
+// Writer returns an io.WriteCloser that buffers the bytes written to it and emits each complete
+// line as a log event, with the prefix, fields and time delta of the logger. Close emits the
+// last line when it is not terminated by a newline.
+//
+// When the logger is disabled the writer is a sink discarding every byte, so it is safe to pipe
+// verbose output into it unconditionally. The lines are still recorded by an active flight recorder.
+//
+// Example:
+//
+//	cmd := exec.Command("make", "build")
+//	w := k.Writer()
+//	defer w.Close()
+//	cmd.Stdout = w
+//	cmd.Stderr = w
+func (k *Kemba) Writer() io.WriteCloser {
+	return &lineWriter{k: k}
+}
+
+// Write will emit each complete line, keeping any trailing partial line for the next write.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if !w.k.isEnabled() && !flight.active.Load() {
+		w.buf = nil
+		return len(p), nil
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	for len(w.buf) >= maxLineLength {
+		w.emit(w.buf[:maxLineLength])
+		w.buf = w.buf[maxLineLength:]
+	}
+
+	return len(p), nil
+}
+
+// Close will emit the buffered partial line. Writing after Close returns io.ErrClosedPipe.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.buf) > 0 && (w.k.isEnabled() || flight.active.Load()) {
+		w.emit(w.buf)
+	}
+	w.buf = nil
+
+	return nil
+}
+
+// emit will log the line, dropping the carriage return of CRLF line endings.
+// The caller is not annotated, as it would always be the writer.
+//
+// The caller must hold the lock.
+func (w *lineWriter) emit(line []byte) {
+	lines := []string{string(bytes.TrimSuffix(line, []byte("\r")))}
+	if flight.active.Load() {
+		w.k.recordLines(lines, w.k.fields)
+	}
+	w.k.logPC(0, lines, w.k.fields)
+}
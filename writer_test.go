@@ -0,0 +1,87 @@
+package kemba
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Writer(t *testing.T) {
+	is := assert.New(t)
+
+	t.Run("should emit each line as a log event", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		w := k.Writer()
+		_, _ = fmt.Fprint(w, "first\nsec")
+		_, _ = fmt.Fprint(w, "ond\r\nthi")
+
+		is.Regexp(`^test:kemba first \+\S+\ntest:kemba second \+\S+\n$`, buf.String())
+
+		is.NoError(w.Close())
+		is.Regexp(`test:kemba thi \+\S+\n$`, buf.String())
+	})
+
+	t.Run("should carry the fields of the logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false))
+		w := k.With("stream", "stdout").Writer()
+		_, _ = io.WriteString(w, "test\n")
+
+		is.Regexp(`^test:kemba test stream=stdout \+\S+\n$`, buf.String())
+	})
+
+	t.Run("should split lines that are too long", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false), WithTime(TimeNone))
+		w := k.Writer()
+		_, _ = io.WriteString(w, strings.Repeat("a", maxLineLength+1))
+		_ = w.Close()
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		is.Len(lines, 2)
+		is.Equal("test:kemba a", lines[1])
+	})
+
+	t.Run("should discard the output when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed(""))
+		w := k.Writer()
+		n, err := io.WriteString(w, "test\npartial")
+
+		is.NoError(err)
+		is.Equal(12, n)
+		is.NoError(w.Close())
+		is.Equal("", buf.String())
+	})
+
+	t.Run("should fail to write after close", func(t *testing.T) {
+		k := NewWithOptions("test:kemba", WithWriter(&bytes.Buffer{}), WithAllowed("test:*"))
+		w := k.Writer()
+		_ = w.Close()
+
+		_, err := io.WriteString(w, "test\n")
+		is.ErrorIs(err, io.ErrClosedPipe)
+	})
+
+	t.Run("should capture the output of a command", func(t *testing.T) {
+		if _, err := exec.LookPath("printf"); err != nil {
+			t.Skip("printf is not available")
+		}
+
+		var buf bytes.Buffer
+		k := NewWithOptions("test:kemba", WithWriter(&buf), WithAllowed("test:*"), WithColor(false), WithTime(TimeNone))
+		w := k.Writer()
+		cmd := exec.Command("printf", "one\ntwo\n")
+		cmd.Stdout = w
+
+		is.NoError(cmd.Run())
+		is.NoError(w.Close())
+		is.Equal("test:kemba one\ntest:kemba two\n", buf.String())
+	})
+}